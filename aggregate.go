@@ -0,0 +1,84 @@
+package squbix
+
+import (
+	"fmt"
+)
+
+// CountQuery derives query counting rows the read query returns, ignoring its order by, limit,
+// offset and locking clauses. Query with group by, having or distinct is counted as subquery.
+func (ths *queryBuilder) CountQuery() *queryBuilder {
+	return ths.aggregateQuery("COUNT(*)")
+}
+
+// SumQuery derives query summing expression over rows the read query returns, ignoring its order
+// by, limit, offset and locking clauses. Query with group by, having or distinct is summed as
+// subquery, the expression must then refer to its selected fields.
+func (ths *queryBuilder) SumQuery(expr string) *queryBuilder {
+	return ths.aggregateQuery(fmt.Sprintf("SUM(%s)", expr))
+}
+
+// ExistsQuery derives query checking whether the read query returns any row, ignoring its order
+// by, limit, offset and locking clauses. Query with having keeps its select list, the having
+// condition may refer to its aliases.
+func (ths *queryBuilder) ExistsQuery() *derivedQueryBuilder {
+	inner := ths.unbounded()
+	inner.distinct = false
+	inner.distinctOn = nil
+
+	if len(inner.havingFragments) == 0 {
+		inner.selectFragments = []Expr{rawExpr("1")}
+		inner.windowFragments = nil
+	}
+
+	return deriveQuery(inner, "SELECT EXISTS(%s)")
+}
+
+func (ths *queryBuilder) aggregateQuery(aggregate string) *queryBuilder {
+	inner := ths.unbounded()
+
+	if len(inner.groupByFragments) == 0 && len(inner.havingFragments) == 0 && !inner.distinct && len(inner.distinctOn) == 0 {
+		inner.selectFragments = []Expr{rawExpr(aggregate)}
+		inner.windowFragments = nil
+
+		return inner
+	}
+
+	outer := &queryBuilder{
+		selectFragments: []Expr{rawExpr(aggregate)},
+		options:         inner.options,
+	}
+
+	expr, err := inner.buildExpr()
+	if err != nil {
+		outer.err = err
+
+		return outer
+	}
+
+	outer.fromFragments = []Expr{formatExpr("(%s) AS aggregated", expr)}
+
+	return outer
+}
+
+// unbounded returns copy of the read query without order by, limit, offset, locking clauses and
+// relations.
+func (ths *queryBuilder) unbounded() *queryBuilder {
+	clone := *ths
+	clone.cteFragments = append([]Expr{}, ths.cteFragments...)
+	clone.selectFragments = append([]Expr{}, ths.selectFragments...)
+	clone.distinctOn = append([]Expr{}, ths.distinctOn...)
+	clone.fromFragments = append([]Expr{}, ths.fromFragments...)
+	clone.joinFragments = append([]Expr{}, ths.joinFragments...)
+	clone.whereFragments = append([]Expr{}, ths.whereFragments...)
+	clone.guards = append([]Expr{}, ths.guards...)
+	clone.groupByFragments = append([]Expr{}, ths.groupByFragments...)
+	clone.havingFragments = append([]Expr{}, ths.havingFragments...)
+	clone.windowFragments = append([]Expr{}, ths.windowFragments...)
+	clone.orderByFragments = nil
+	clone.limit = nil
+	clone.offset = nil
+	clone.locks = nil
+	clone.relations = nil
+
+	return &clone
+}
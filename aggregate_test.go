@@ -0,0 +1,105 @@
+package squbix
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAggregateQuery(t *testing.T) {
+	Convey("Given paginated read query", t, func() {
+		builder := NewReadQuery("orders").
+			WithDialect(Postgres).
+			AddSelect("id", "total").
+			AddJoin("JOIN customers ON customers.id = orders.customer_id").
+			AddWhereExpr(NewExpr("orders.status = ?", "paid"), NewExpr("customers.region = ?", "eu")).
+			AddOrderBy("id DESC").
+			AddLimit(20).
+			AddOffset(40).
+			ForShare()
+
+		countQuery, countArgs, countErr := builder.CountQuery().BuildQueryWithArgs()
+		sumQuery, sumArgs, sumErr := builder.SumQuery("total").BuildQueryWithArgs()
+		existsQuery, existsArgs, existsErr := builder.ExistsQuery().BuildQueryWithArgs()
+		query, args, err := builder.BuildQueryWithArgs()
+
+		Convey("It should derive queries keeping conditions and arguments", func() {
+			So(countErr, ShouldBeNil)
+			So(countQuery, ShouldEqual, "SELECT COUNT(*) FROM orders JOIN customers ON customers.id = orders.customer_id WHERE orders.status = $1 AND customers.region = $2")
+			So(countArgs, ShouldResemble, []interface{}{"paid", "eu"})
+			So(sumErr, ShouldBeNil)
+			So(sumQuery, ShouldEqual, "SELECT SUM(total) FROM orders JOIN customers ON customers.id = orders.customer_id WHERE orders.status = $1 AND customers.region = $2")
+			So(sumArgs, ShouldResemble, []interface{}{"paid", "eu"})
+			So(existsErr, ShouldBeNil)
+			So(existsQuery, ShouldEqual, "SELECT EXISTS(SELECT 1 FROM orders JOIN customers ON customers.id = orders.customer_id WHERE orders.status = $1 AND customers.region = $2)")
+			So(existsArgs, ShouldResemble, []interface{}{"paid", "eu"})
+		})
+
+		Convey("It should leave the original query untouched", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT id, total FROM orders JOIN customers ON customers.id = orders.customer_id WHERE orders.status = $1 AND customers.region = $2 ORDER BY id DESC LIMIT 20 OFFSET 40 FOR SHARE")
+			So(args, ShouldResemble, []interface{}{"paid", "eu"})
+		})
+	})
+
+	Convey("Given grouped and distinct read queries", t, func() {
+		grouped, groupedArgs, groupedErr := NewReadQuery("orders").
+			AddSelect("customer_id", "SUM(total) AS total").
+			AddWhereExpr(NewExpr("status = ?", "paid")).
+			AddGroupBy("customer_id").
+			AddHavingExpr(NewExpr("SUM(total) > ?", 100)).
+			AddOrderBy("customer_id").
+			AddLimit(10).
+			CountQuery().
+			BuildQueryWithArgs()
+		distinct, distinctErr := NewReadQuery("orders").
+			AddSelect("customer_id").
+			Distinct().
+			SumQuery("customer_id").
+			BuildQuery()
+
+		Convey("It should aggregate over subquery", func() {
+			So(groupedErr, ShouldBeNil)
+			So(grouped, ShouldEqual, "SELECT COUNT(*) FROM (SELECT customer_id, SUM(total) AS total FROM orders WHERE status = ? GROUP BY customer_id HAVING SUM(total) > ?) AS aggregated")
+			So(groupedArgs, ShouldResemble, []interface{}{"paid", 100})
+			So(distinctErr, ShouldBeNil)
+			So(distinct, ShouldEqual, "SELECT SUM(customer_id) FROM (SELECT DISTINCT customer_id FROM orders) AS aggregated")
+		})
+	})
+
+	Convey("Given grouped read query with having on select alias", t, func() {
+		query, args, err := NewReadQuery("orders").
+			AddSelect("customer_id", "SUM(total) AS total").
+			AddWhereExpr(NewExpr("status = ?", "paid")).
+			AddGroupBy("customer_id").
+			AddHavingExpr(NewExpr("total > ?", 100)).
+			AddOrderBy("total DESC").
+			ExistsQuery().
+			BuildQueryWithArgs()
+
+		Convey("It should check existence keeping select list and dropping order by", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT EXISTS(SELECT customer_id, SUM(total) AS total FROM orders WHERE status = ? GROUP BY customer_id HAVING total > ?)")
+			So(args, ShouldResemble, []interface{}{"paid", 100})
+		})
+	})
+
+	Convey("Given invalid read query", t, func() {
+		_, countErr := NewReadQuery("orders").
+			AddGroupBy("customer_id").
+			CountQuery().
+			BuildQuery()
+		_, existsErr := NewReadQuery("").
+			AddSelect("id").
+			AddWhereFilter(1).
+			ExistsQuery().
+			BuildQuery()
+
+		Convey("It should returns error of the original query", func() {
+			So(countErr, ShouldNotBeNil)
+			So(countErr.Error(), ShouldEqual, "no field selected, add it using AddSelect method")
+			So(existsErr, ShouldNotBeNil)
+			So(existsErr.Error(), ShouldEqual, "filter must be struct or pointer to struct, got int")
+		})
+	})
+}
@@ -0,0 +1,79 @@
+package squbix
+
+// Builder is implemented by every query builder of this package, it lets helpers such as Explain
+// wrap any of them.
+type Builder interface {
+	// BuildQuery generates final query string.
+	BuildQuery() (string, error)
+	// BuildQueryWithArgs generates final query string and arguments bound to its placeholders.
+	BuildQueryWithArgs() (string, []interface{}, error)
+	// DebugString returns query with arguments inlined as escaped literals for logs, it must
+	// never be executed.
+	DebugString() string
+
+	buildExpr() (Expr, error)
+	buildOptions() renderOptions
+	shape() Shape
+}
+
+type derivedQueryBuilder struct {
+	build   func() (Expr, error)
+	options renderOptions
+	source  Builder
+}
+
+// DerivedQueryBuilder is sql builder for query derived from other builder, e.g. by ExistsQuery, it
+// allows the builder to be referred from other packages.
+type DerivedQueryBuilder = derivedQueryBuilder
+
+// deriveQuery creates builder wrapping expression of source builder with format, source
+// expression replaces %s verb of the format.
+func deriveQuery(source Builder, format string) *derivedQueryBuilder {
+	return &derivedQueryBuilder{
+		build: func() (Expr, error) {
+			expr, err := source.buildExpr()
+			if err != nil {
+				return Expr{}, err
+			}
+
+			return formatExpr(format, expr), nil
+		},
+		options: source.buildOptions(),
+		source:  source,
+	}
+}
+
+// BuildQuery generates final query string.
+func (ths *derivedQueryBuilder) BuildQuery() (string, error) {
+	query, _, err := ths.BuildQueryWithArgs()
+
+	return query, err
+}
+
+// BuildQueryWithArgs generates final query string and arguments bound to its placeholders.
+func (ths *derivedQueryBuilder) BuildQueryWithArgs() (string, []interface{}, error) {
+	expr, err := ths.buildExpr()
+	if err != nil {
+		return "", nil, err
+	}
+
+	return renderExpr(expr, ths.options)
+}
+
+// DebugString returns query with arguments inlined as escaped literals for logs, it must never be
+// executed.
+func (ths *derivedQueryBuilder) DebugString() string {
+	return debugString(ths)
+}
+
+func (ths *derivedQueryBuilder) buildExpr() (Expr, error) {
+	return ths.build()
+}
+
+func (ths *derivedQueryBuilder) buildOptions() renderOptions {
+	return ths.options
+}
+
+func (ths *derivedQueryBuilder) shape() Shape {
+	return ths.source.shape()
+}
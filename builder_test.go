@@ -0,0 +1,37 @@
+package squbix
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestBuilder(t *testing.T) {
+	Convey("Given builders of every operation", t, func() {
+		builders := []Builder{
+			NewReadQuery("table_a").AddSelect("field_a"),
+			NewCreateQuery("table_a").AddField("field_a").AddValue("(1)"),
+			NewUpdateQuery("table_a").AddSetField("field_a = 1").AddWhere("id = 1"),
+			NewDeleteQuery("table_a").AddWhere("id = 1"),
+			NewReadQuery("table_a").AddSelect("field_a").ExistsQuery(),
+		}
+
+		Convey("It should build each of them through the interface", func() {
+			queries := []string{}
+			for _, builder := range builders {
+				query, err := builder.BuildQuery()
+				So(err, ShouldBeNil)
+
+				queries = append(queries, query)
+			}
+
+			So(queries, ShouldResemble, []string{
+				"SELECT field_a FROM table_a",
+				"INSERT INTO table_a (field_a) VALUES (1)",
+				"UPDATE table_a SET field_a = 1 WHERE id = 1",
+				"DELETE FROM table_a WHERE id = 1",
+				"SELECT EXISTS(SELECT 1 FROM table_a)",
+			})
+		})
+	})
+}
@@ -0,0 +1,187 @@
+package squbix
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// CaseExpr is CASE expression usable in select, order by and set clauses and conditions.
+// Results are bound as arguments unless they are Expr, use NewExpr to return column or other
+// expression.
+type CaseExpr struct {
+	operand  *Expr
+	branches []Expr
+	result   *Expr
+}
+
+// NewCase creates searched CASE expression returning result of the first branch whose condition
+// holds.
+func NewCase() *CaseExpr {
+	return &CaseExpr{}
+}
+
+// NewSimpleCase creates simple CASE expression returning result of the first branch whose value
+// equals the operand.
+func NewSimpleCase(operand string) *CaseExpr {
+	expr := rawExpr(operand)
+
+	return &CaseExpr{
+		operand: &expr,
+	}
+}
+
+// When adds branch returning result. Condition of searched CASE is raw fragment, like the ones of
+// AddWhere, or Expr with bound arguments. Value of simple CASE is bound as argument unless it is
+// Expr.
+func (ths *CaseExpr) When(condition interface{}, result interface{}) *CaseExpr {
+	var when Expr
+
+	switch {
+	case ths.operand != nil:
+		when = caseValue(condition)
+	default:
+		if fragment, ok := condition.(string); ok {
+			when = rawExpr(fragment)
+		} else {
+			when = caseValue(condition)
+		}
+	}
+
+	ths.branches = append(ths.branches, formatExpr("WHEN %s THEN %s", when, caseValue(result)))
+
+	return ths
+}
+
+// Else sets result returned when no branch matches, NULL is returned when it is not set.
+func (ths *CaseExpr) Else(result interface{}) *CaseExpr {
+	expr := caseValue(result)
+	ths.result = &expr
+
+	return ths
+}
+
+// Expr returns the CASE expression.
+func (ths *CaseExpr) Expr() Expr {
+	if len(ths.branches) == 0 {
+		if ths.result == nil {
+			return rawExpr("NULL")
+		}
+
+		return *ths.result
+	}
+
+	fragments := []Expr{rawExpr("CASE")}
+
+	if ths.operand != nil {
+		fragments = append(fragments, *ths.operand)
+	}
+
+	fragments = append(fragments, ths.branches...)
+
+	if ths.result != nil {
+		fragments = append(fragments, formatExpr("ELSE %s", *ths.result))
+	}
+
+	return joinExprs(append(fragments, rawExpr("END")), " ")
+}
+
+// Compare returns condition comparing the CASE expression to value using operator, e.g. "=" or
+// "<>". Value is bound as argument unless it is Expr.
+func (ths *CaseExpr) Compare(operator string, value interface{}) Expr {
+	return formatExpr("%s "+operator+" %s", ths.Expr(), caseValue(value))
+}
+
+// caseValue returns value as expression, binding it as argument unless it is Expr.
+func caseValue(value interface{}) Expr {
+	if expr, ok := value.(Expr); ok {
+		return expr
+	}
+
+	return NewExpr("?", value)
+}
+
+// Assign returns set clause assigning expression to column, e.g. CASE expression for
+// AddSetFieldExpr.
+func Assign(column string, value Expr) Expr {
+	return formatExpr("%s = %s", rawExpr(column), value)
+}
+
+// BulkUpdate sets columns of many rows identified by key column to values of their row in single
+// update statement.
+type BulkUpdate struct {
+	key     string
+	columns []string
+	keys    []interface{}
+	seen    map[interface{}]bool
+	rows    [][]interface{}
+	err     error
+}
+
+// NewBulkUpdate creates bulk update of columns of rows identified by key column.
+func NewBulkUpdate(key string, columns ...string) *BulkUpdate {
+	return &BulkUpdate{
+		key:     key,
+		columns: columns,
+	}
+}
+
+// Row sets columns of row identified by key to values, given in order of the columns. Every row
+// must have its own key.
+func (ths *BulkUpdate) Row(key interface{}, values ...interface{}) *BulkUpdate {
+	switch {
+	case ths.err != nil:
+	case key == nil || !reflect.TypeOf(key).Comparable():
+		ths.err = fmt.Errorf("bulk update row key %v can't be compared", key)
+	case ths.seen[key]:
+		ths.err = fmt.Errorf("bulk update has more than one row of key %v", key)
+	case len(values) != len(ths.columns):
+		ths.err = fmt.Errorf("bulk update row %v has %d value(s) but %d column(s)", key, len(values), len(ths.columns))
+	default:
+		if ths.seen == nil {
+			ths.seen = map[interface{}]bool{}
+		}
+
+		ths.seen[key] = true
+	}
+
+	ths.keys = append(ths.keys, key)
+	ths.rows = append(ths.rows, values)
+
+	return ths
+}
+
+// AddBulkSet adds set clause of every column of bulk update choosing value by key using CASE, rows
+// not in the bulk update are excluded by where clause.
+func (ths *updateQueryBuilder) AddBulkSet(bulk *BulkUpdate) *updateQueryBuilder {
+	switch {
+	case bulk.err != nil:
+		ths.err = bulk.err
+
+		return ths
+	case len(bulk.columns) == 0:
+		ths.err = errors.New("bulk update has no column, add it using NewBulkUpdate function")
+
+		return ths
+	case len(bulk.rows) == 0:
+		ths.err = errors.New("bulk update has no row, add it using Row method")
+
+		return ths
+	}
+
+	for index, column := range bulk.columns {
+		value := NewSimpleCase(bulk.key)
+		for row, key := range bulk.keys {
+			value.When(key, bulk.rows[row][index])
+		}
+
+		// keeping column as is makes postgres infer type of bound values from the column.
+		value.Else(rawExpr(column))
+
+		ths.AddSetFieldExpr(Assign(column, value.Expr()))
+	}
+
+	ths.guards = append(ths.guards, NewExpr(bulk.key+" IN (?)", bulk.keys))
+
+	return ths
+}
@@ -0,0 +1,129 @@
+package squbix
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCaseExpr(t *testing.T) {
+	Convey("Given searched case expressions in select, where and order by", t, func() {
+		tier := NewCase().
+			When("total >= 1000", "gold").
+			When(NewExpr("total >= ?", 100), "silver").
+			Else("bronze")
+		priority := NewCase().
+			When(NewExpr("status = ?", "urgent"), 0).
+			Else(1)
+
+		query, args, err := NewReadQuery("orders").
+			WithDialect(Postgres).
+			AddSelect("id").
+			AddSelectExpr(tier.Expr().As("tier")).
+			AddWhereExpr(NewExpr("customer_id = ?", 7), tier.Compare("<>", "bronze")).
+			AddOrderByExpr(priority.Expr()).
+			BuildQueryWithArgs()
+
+		Convey("It should bind results and condition arguments in order", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT id, CASE WHEN total >= 1000 THEN $1 WHEN total >= $2 THEN $3 ELSE $4 END AS tier FROM orders "+
+				"WHERE customer_id = $5 AND CASE WHEN total >= 1000 THEN $6 WHEN total >= $7 THEN $8 ELSE $9 END <> $10 "+
+				"ORDER BY CASE WHEN status = $11 THEN $12 ELSE $13 END")
+			So(args, ShouldResemble, []interface{}{"gold", 100, "silver", "bronze", 7, "gold", 100, "silver", "bronze", "bronze", "urgent", 0, 1})
+		})
+	})
+
+	Convey("Given simple case expression assigned in update", t, func() {
+		status := NewSimpleCase("status").
+			When("pending", "processing").
+			When(NewExpr("?", "processing"), NewExpr("UPPER(status)"))
+
+		query, args, err := NewUpdateQuery("orders").
+			AddSetFieldExpr(Assign("status", status.Expr())).
+			AddWhere("id = 1").
+			BuildQueryWithArgs()
+
+		Convey("It should compare operand to bound values and return NULL when nothing matches", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "UPDATE orders SET status = CASE status WHEN ? THEN ? WHEN ? THEN UPPER(status) END WHERE id = 1")
+			So(args, ShouldResemble, []interface{}{"pending", "processing", "processing"})
+		})
+	})
+
+	Convey("Given case expression without branch", t, func() {
+		query, args, err := NewReadQuery("orders").
+			AddSelectExpr(NewCase().Expr().As("nothing"), NewSimpleCase("id").Else(1).Expr().As("one")).
+			AddWhereExpr(NewCase().Compare("IS", NewExpr("NULL"))).
+			BuildQueryWithArgs()
+
+		Convey("It should be NULL or the else result", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT NULL AS nothing, ? AS one FROM orders WHERE NULL IS NULL")
+			So(args, ShouldResemble, []interface{}{1})
+		})
+	})
+}
+
+func TestBulkUpdate(t *testing.T) {
+	Convey("Given bulk update of many rows", t, func() {
+		bulk := NewBulkUpdate("id", "status", "total").
+			Row(1, "paid", 10).
+			Row(2, "shipped", 20)
+
+		query, args, err := NewUpdateQuery("orders").
+			WithDialect(Postgres).
+			AddSetField("updated_at = NOW()").
+			AddBulkSet(bulk).
+			BuildQueryWithArgs()
+
+		Convey("It should set values by key and update only the rows", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "UPDATE orders SET updated_at = NOW(), "+
+				"status = CASE id WHEN $1 THEN $2 WHEN $3 THEN $4 ELSE status END, "+
+				"total = CASE id WHEN $5 THEN $6 WHEN $7 THEN $8 ELSE total END "+
+				"WHERE id IN ($9, $10)")
+			So(args, ShouldResemble, []interface{}{1, "paid", 2, "shipped", 1, 10, 2, 20, 1, 2})
+		})
+	})
+
+	Convey("Given bulk update with top-level OR condition without auto parenthesis", t, func() {
+		query, args, err := NewUpdateQuery("orders").
+			WithoutAutoParenthesis().
+			AddBulkSet(NewBulkUpdate("id", "status").Row(1, "paid")).
+			AddWhere("status = 'new' OR status = 'pending'").
+			BuildQueryWithArgs()
+
+		Convey("It should keep key condition outside of the OR", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "UPDATE orders SET status = CASE id WHEN ? THEN ? ELSE status END "+
+				"WHERE (status = 'new' OR status = 'pending') AND id IN (?)")
+			So(args, ShouldResemble, []interface{}{1, "paid", 1})
+		})
+	})
+
+	Convey("Given invalid bulk updates", t, func() {
+		_, mismatchErr := NewUpdateQuery("orders").
+			AddBulkSet(NewBulkUpdate("id", "status", "total").Row(1, "paid")).
+			BuildQuery()
+		_, noRowErr := NewUpdateQuery("orders").
+			AddBulkSet(NewBulkUpdate("id", "status")).
+			BuildQuery()
+		_, noColumnErr := NewUpdateQuery("orders").
+			AddBulkSet(NewBulkUpdate("id").Row(1)).
+			BuildQuery()
+		_, duplicateErr := NewUpdateQuery("orders").
+			AddBulkSet(NewBulkUpdate("id", "status").Row(1, "paid").Row(2, "paid").Row(1, "shipped")).
+			BuildQuery()
+		_, uncomparableErr := NewUpdateQuery("orders").
+			AddBulkSet(NewBulkUpdate("id", "status").Row([]int{1}, "paid")).
+			BuildQuery()
+
+		Convey("It should return the errors", func() {
+			So(mismatchErr, ShouldBeError, "bulk update row 1 has 1 value(s) but 2 column(s)")
+			So(noRowErr, ShouldBeError, "bulk update has no row, add it using Row method")
+			So(noColumnErr, ShouldBeError, "bulk update has no column, add it using NewBulkUpdate function")
+			So(duplicateErr, ShouldBeError, "bulk update has more than one row of key 1")
+			So(uncomparableErr, ShouldBeError, "bulk update row key [1] can't be compared")
+		})
+	})
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/nicklaros/squbix"
+)
+
+var initialisms = map[string]bool{
+	"API":   true,
+	"ASCII": true,
+	"CSS":   true,
+	"DNS":   true,
+	"HTML":  true,
+	"HTTP":  true,
+	"ID":    true,
+	"IP":    true,
+	"JSON":  true,
+	"SQL":   true,
+	"URI":   true,
+	"URL":   true,
+	"UUID":  true,
+	"XML":   true,
+}
+
+// reservedWords are common sql keywords which have to be quoted when used as identifier.
+var reservedWords = map[string]bool{
+	"all": true, "and": true, "as": true, "by": true, "case": true, "check": true, "column": true,
+	"constraint": true, "create": true, "default": true, "delete": true, "desc": true, "distinct": true,
+	"from": true, "group": true, "having": true, "in": true, "insert": true, "into": true, "is": true,
+	"join": true, "key": true, "limit": true, "not": true, "null": true, "offset": true, "on": true,
+	"or": true, "order": true, "primary": true, "references": true, "select": true, "set": true,
+	"table": true, "to": true, "union": true, "unique": true, "update": true, "user": true, "using": true,
+	"values": true, "when": true, "where": true, "with": true,
+}
+
+var simpleIdentifier = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// reservedFields are names of methods generated on table type, columns can't use them as field names.
+var reservedFields = map[string]bool{
+	"Columns": true,
+	"Select":  true,
+	"Insert":  true,
+	"Update":  true,
+	"Delete":  true,
+}
+
+var tableTemplate = template.Must(template.New("table").Parse(`// Code generated by squbix-gen. DO NOT EDIT.
+
+package {{ .Package }}
+
+import "github.com/nicklaros/squbix"
+
+// {{ .GoName }}TableName is name of {{ .Name }} table.
+const {{ .GoName }}TableName = {{ printf "%q" .Identifier }}
+
+// {{ .GoName }}Column is column of {{ .Name }} table, queries of the table accept only its columns.
+type {{ .GoName }}Column struct {
+	squbix.TableColumn
+}
+
+// {{ .GoName }}Table holds columns of {{ .Name }} table.
+type {{ .GoName }}Table struct {
+{{- range .Columns }}
+	// {{ .GoName }} is {{ .Name }} column{{ if .Type }} of {{ .Type }} type{{ end }}{{ if not .Nullable }}, not null{{ end }}.
+	{{ .GoName }} {{ $.GoName }}Column
+{{- end }}
+}
+
+// {{ .GoName }} is {{ .Name }} table.
+var {{ .GoName }} = {{ .GoName }}Table{
+{{- range .Columns }}
+	{{ .GoName }}: {{ $.GoName }}Column{squbix.TableColumn{Table: {{ $.GoName }}TableName, Name: {{ printf "%q" .Identifier }}}},
+{{- end }}
+}
+
+// Columns returns all columns of {{ .Name }} table.
+func (ths {{ .GoName }}Table) Columns() []{{ .GoName }}Column {
+	return []{{ .GoName }}Column{
+{{- range .Columns }}
+		ths.{{ .GoName }},
+{{- end }}
+	}
+}
+
+// Select creates read query selecting the columns from {{ .Name }} table.
+func (ths {{ .GoName }}Table) Select(columns ...{{ .GoName }}Column) *squbix.ReadQueryBuilder {
+	return squbix.NewReadQuery({{ .GoName }}TableName).AddSelect(squbix.QualifiedColumnNames(ths.tableColumns(columns)...)...)
+}
+
+// Insert creates create query inserting the columns into {{ .Name }} table.
+func (ths {{ .GoName }}Table) Insert(columns ...{{ .GoName }}Column) *squbix.CreateQueryBuilder {
+	return squbix.NewCreateQuery({{ .GoName }}TableName).AddField(squbix.ColumnNames(ths.tableColumns(columns)...)...)
+}
+
+// Update creates update query on {{ .Name }} table.
+func (ths {{ .GoName }}Table) Update() *squbix.UpdateQueryBuilder {
+	return squbix.NewUpdateQuery({{ .GoName }}TableName)
+}
+
+// Delete creates delete query on {{ .Name }} table.
+func (ths {{ .GoName }}Table) Delete() *squbix.DeleteQueryBuilder {
+	return squbix.NewDeleteQuery({{ .GoName }}TableName)
+}
+
+func (ths {{ .GoName }}Table) tableColumns(columns []{{ .GoName }}Column) []squbix.TableColumn {
+	tableColumns := make([]squbix.TableColumn, 0, len(columns))
+	for _, column := range columns {
+		tableColumns = append(tableColumns, column.TableColumn)
+	}
+
+	return tableColumns
+}
+`))
+
+type tableData struct {
+	Package    string
+	Name       string
+	Identifier string
+	GoName     string
+	Columns    []columnData
+}
+
+type columnData struct {
+	Name       string
+	Identifier string
+	GoName     string
+	Type       string
+	Nullable   bool
+}
+
+// loadSchema loads schema from DDL dump or, when the file has .json extension, from squbix
+// schema encoded as JSON.
+func loadSchema(path string) (*squbix.Schema, error) {
+	if !strings.EqualFold(filepath.Ext(path), ".json") {
+		return squbix.LoadSchemaFromDDLFile(path)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := squbix.NewSchema()
+	if err := json.Unmarshal(data, schema); err != nil {
+		return nil, err
+	}
+
+	return schema, nil
+}
+
+// generate renders source file of each table in the schema, keyed by file name.
+func generate(schema *squbix.Schema, packageName string) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	goNames := map[string]string{}
+
+	for _, table := range schema.Tables() {
+		data := tableData{
+			Package:    packageName,
+			Name:       table.Name,
+			Identifier: identifier(table.Name),
+			GoName:     goName(table.Name, "T"),
+		}
+
+		for _, name := range []string{data.GoName, data.GoName + "TableName", data.GoName + "Column", data.GoName + "Table"} {
+			if other, ok := goNames[name]; ok {
+				return nil, fmt.Errorf("tables %q and %q have the same Go name %s", other, table.Name, name)
+			}
+
+			goNames[name] = table.Name
+		}
+
+		fields := map[string]string{}
+		for _, column := range table.Columns {
+			field := goName(column.Name, "C")
+			if reservedFields[field] {
+				field += "Column"
+			}
+
+			if other, ok := fields[field]; ok {
+				return nil, fmt.Errorf("columns %q and %q of table %q have the same Go name %s", other, column.Name, table.Name, field)
+			}
+
+			fields[field] = column.Name
+			data.Columns = append(data.Columns, columnData{
+				Name:       column.Name,
+				Identifier: identifier(column.Name),
+				GoName:     field,
+				Type:       column.Type,
+				Nullable:   column.Nullable,
+			})
+		}
+
+		source := &bytes.Buffer{}
+		if err := tableTemplate.Execute(source, data); err != nil {
+			return nil, err
+		}
+
+		formatted, err := format.Source(source.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("table %q: %s", table.Name, err)
+		}
+
+		files[fileName(table.Name)] = formatted
+	}
+
+	return files, nil
+}
+
+// identifier returns name ready to be used in query, quoting it when it is not simple lower case
+// identifier or it is reserved word.
+func identifier(name string) string {
+	if simpleIdentifier.MatchString(name) && !reservedWords[name] {
+		return name
+	}
+
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
+
+// goName converts sql name like order_items into exported Go name like OrderItems, names not
+// starting with upper case letter are prefixed.
+func goName(name string, prefix string) string {
+	parts := strings.FieldsFunc(name, func(char rune) bool {
+		return !unicode.IsLetter(char) && !unicode.IsDigit(char)
+	})
+
+	result := ""
+	for _, part := range parts {
+		if initialisms[strings.ToUpper(part)] {
+			result += strings.ToUpper(part)
+
+			continue
+		}
+
+		runes := []rune(strings.ToLower(part))
+		runes[0] = unicode.ToUpper(runes[0])
+		result += string(runes)
+	}
+
+	if len(result) == 0 || !unicode.IsUpper([]rune(result)[0]) {
+		result = prefix + result
+	}
+
+	return result
+}
+
+// fileName returns name of generated file for table, the _table suffix keeps go tool from
+// treating it as test or platform specific file.
+func fileName(table string) string {
+	name := strings.Map(func(char rune) rune {
+		if unicode.IsLetter(char) || unicode.IsDigit(char) {
+			return unicode.ToLower(char)
+		}
+
+		return '_'
+	}, table)
+
+	return name + "_table.go"
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"flag"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/nicklaros/squbix"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestGenerate(t *testing.T) {
+	for _, schemaPath := range []string{"testdata/schema.sql", "testdata/schema.json"} {
+		Convey("Given schema from "+schemaPath, t, func() {
+			schema, err := loadSchema(schemaPath)
+			So(err, ShouldBeNil)
+
+			files, err := generate(schema, "models")
+
+			Convey("It should generate file per table matching golden files", func() {
+				So(err, ShouldBeNil)
+				So(len(files), ShouldEqual, 2)
+
+				for name, source := range files {
+					golden := filepath.Join("testdata", name+".golden")
+					if *update {
+						So(ioutil.WriteFile(golden, source, 0644), ShouldBeNil)
+					}
+
+					expected, err := ioutil.ReadFile(golden)
+
+					So(err, ShouldBeNil)
+					So(string(source), ShouldEqual, string(expected))
+				}
+			})
+		})
+	}
+}
+
+// typeCheck type checks generated files together with file using them.
+func typeCheck(files map[string][]byte, usage string) error {
+	fileSet := token.NewFileSet()
+	parsed := []*ast.File{}
+
+	sources := map[string]string{"usage.go": "package models\n\nfunc usage() {\n" + usage + "\n}\n"}
+	for name, source := range files {
+		sources[name] = string(source)
+	}
+
+	for name, source := range sources {
+		file, err := parser.ParseFile(fileSet, name, source, 0)
+		if err != nil {
+			return err
+		}
+
+		parsed = append(parsed, file)
+	}
+
+	config := types.Config{Importer: importer.ForCompiler(fileSet, "source", nil)}
+	_, err := config.Check("models", fileSet, parsed, nil)
+
+	return err
+}
+
+func TestGeneratedCode(t *testing.T) {
+	Convey("Given generated files", t, func() {
+		schema, err := loadSchema("testdata/schema.sql")
+		So(err, ShouldBeNil)
+
+		files, err := generate(schema, "models")
+		So(err, ShouldBeNil)
+
+		Convey("It should compile using columns of the table", func() {
+			So(typeCheck(files, `
+	_ = Users.Select(Users.ID, Users.Email).AddWhere("id = 1")
+	_ = OrderItems.Insert(OrderItems.Columns()...)
+	_ = Users.ID.String()`), ShouldBeNil)
+		})
+
+		Convey("It should not compile using columns of other table", func() {
+			err := typeCheck(files, `_ = Users.Select(OrderItems.ID)`)
+
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "OrderItemsColumn")
+		})
+	})
+
+	Convey("Given tables whose generated type names collide", t, func() {
+		_, err := generate(squbix.NewSchema(squbix.Table{Name: "users"}, squbix.Table{Name: "users_column"}), "models")
+
+		Convey("It should returns error", func() {
+			So(err, ShouldBeError, `tables "users" and "users_column" have the same Go name UsersColumn`)
+		})
+	})
+}
+
+func TestIdentifier(t *testing.T) {
+	Convey("Given sql names", t, func() {
+		Convey("It should quote the ones which can't be used as is", func() {
+			So(identifier("order_items"), ShouldEqual, "order_items")
+			So(identifier("select"), ShouldEqual, `"select"`)
+			So(identifier("2fa_secret"), ShouldEqual, `"2fa_secret"`)
+			So(identifier(`Display "Name"`), ShouldEqual, `"Display ""Name"""`)
+		})
+	})
+}
+
+func TestGoName(t *testing.T) {
+	Convey("Given sql names", t, func() {
+		Convey("It should convert them into exported Go names", func() {
+			So(goName("order_items", "T"), ShouldEqual, "OrderItems")
+			So(goName("user_id", "C"), ShouldEqual, "UserID")
+			So(goName("display name", "C"), ShouldEqual, "DisplayName")
+			So(goName("2fa_secret", "C"), ShouldEqual, "C2faSecret")
+		})
+	})
+}
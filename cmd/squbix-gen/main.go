@@ -0,0 +1,64 @@
+// Command squbix-gen generates Go package with typed table and column references from schema
+// described by SQL DDL dump or JSON file, so query builders can be created without raw names.
+//
+// Usage:
+//
+//	squbix-gen -schema schema.sql -out ./models -package models
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	schemaPath := flag.String("schema", "", "path to schema file, .sql for DDL dump or .json for squbix schema")
+	output := flag.String("out", ".", "directory to write generated files into")
+	packageName := flag.String("package", "", "name of generated package, defaults to name of output directory")
+	flag.Parse()
+
+	if err := run(*schemaPath, *output, *packageName); err != nil {
+		fmt.Fprintln(os.Stderr, "squbix-gen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(schemaPath string, output string, packageName string) error {
+	if len(schemaPath) == 0 {
+		return fmt.Errorf("no schema specified, set it using -schema flag")
+	}
+
+	schema, err := loadSchema(schemaPath)
+	if err != nil {
+		return err
+	}
+
+	if len(packageName) == 0 {
+		absolute, err := filepath.Abs(output)
+		if err != nil {
+			return err
+		}
+
+		packageName = filepath.Base(absolute)
+	}
+
+	files, err := generate(schema, packageName)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(output, 0755); err != nil {
+		return err
+	}
+
+	for name, source := range files {
+		if err := ioutil.WriteFile(filepath.Join(output, name), source, 0644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,14 @@
+CREATE TABLE users (
+    id bigint PRIMARY KEY,
+    email character varying(255) NOT NULL UNIQUE,
+    api_key uuid,
+    "2fa_secret" text,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+CREATE TABLE order_items (
+    id bigserial PRIMARY KEY,
+    order_id bigint NOT NULL,
+    "select" text,
+    quantity integer DEFAULT 1 NOT NULL
+);
@@ -0,0 +1,32 @@
+package squbix
+
+// TableColumn refers to column of a table, it is used by code generated with squbix-gen.
+type TableColumn struct {
+	Table string
+	Name  string
+}
+
+// String returns column name qualified by its table name.
+func (ths TableColumn) String() string {
+	return ths.Table + "." + ths.Name
+}
+
+// ColumnNames returns unqualified names of the columns.
+func ColumnNames(columns ...TableColumn) []string {
+	names := make([]string, 0, len(columns))
+	for _, column := range columns {
+		names = append(names, column.Name)
+	}
+
+	return names
+}
+
+// QualifiedColumnNames returns names of the columns qualified by their table names.
+func QualifiedColumnNames(columns ...TableColumn) []string {
+	names := make([]string, 0, len(columns))
+	for _, column := range columns {
+		names = append(names, column.String())
+	}
+
+	return names
+}
@@ -0,0 +1,22 @@
+package squbix
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTableColumn(t *testing.T) {
+	Convey("Given table columns", t, func() {
+		columns := []TableColumn{
+			{Table: "users", Name: "id"},
+			{Table: "users", Name: "email"},
+		}
+
+		Convey("It should returns their names", func() {
+			So(columns[0].String(), ShouldEqual, "users.id")
+			So(ColumnNames(columns...), ShouldResemble, []string{"id", "email"})
+			So(QualifiedColumnNames(columns...), ShouldResemble, []string{"users.id", "users.email"})
+		})
+	})
+}
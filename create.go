@@ -2,19 +2,24 @@ package squbix
 
 import (
 	"errors"
-	"fmt"
-	"strings"
 )
 
 type createQueryBuilder struct {
-	cteFragments            []string
+	cteFragments            []Expr
 	intoFragment            string
 	fieldFragments          []string
-	valueFragments          []string
-	valueWithSelectFragment string
-	onConflictFragment      string
+	valueFragments          []Expr
+	valueWithSelectFragment Expr
+	onConflictFragment      Expr
+	schema                  *Schema
+	lint                    *LintOptions
+	options                 renderOptions
+	err                     error
 }
 
+// CreateQueryBuilder is sql builder for insert operation, it allows the builder to be referred from other packages.
+type CreateQueryBuilder = createQueryBuilder
+
 // NewCreateQuery creates new sql builder instance for insert operation.
 func NewCreateQuery(table string) *createQueryBuilder {
 	return &createQueryBuilder{
@@ -24,7 +29,7 @@ func NewCreateQuery(table string) *createQueryBuilder {
 
 // AddCTE adds common table expression to include in generated query.
 func (ths *createQueryBuilder) AddCTE(CTEs ...string) *createQueryBuilder {
-	ths.cteFragments = append(ths.cteFragments, CTEs...)
+	ths.cteFragments = append(ths.cteFragments, rawExprs(CTEs)...)
 
 	return ths
 }
@@ -38,6 +43,13 @@ func (ths *createQueryBuilder) AddField(fields ...string) *createQueryBuilder {
 
 // AddValue adds value to insert in generated query.
 func (ths *createQueryBuilder) AddValue(values ...string) *createQueryBuilder {
+	ths.valueFragments = append(ths.valueFragments, rawExprs(values)...)
+
+	return ths
+}
+
+// AddValueExpr adds value with bound arguments to insert in generated query.
+func (ths *createQueryBuilder) AddValueExpr(values ...Expr) *createQueryBuilder {
 	ths.valueFragments = append(ths.valueFragments, values...)
 
 	return ths
@@ -45,65 +57,195 @@ func (ths *createQueryBuilder) AddValue(values ...string) *createQueryBuilder {
 
 // AddValueWithSelect adds value with select in generated query.
 func (ths *createQueryBuilder) AddValueWithSelect(valueWithSelect string) *createQueryBuilder {
-	ths.valueWithSelectFragment = valueWithSelect
+	ths.valueWithSelectFragment = rawExpr(valueWithSelect)
 
 	return ths
 }
 
 // AddOnConflict adds on conflict in generated query.
 func (ths *createQueryBuilder) AddOnConflict(onConflict string) *createQueryBuilder {
-	ths.onConflictFragment = onConflict
+	ths.onConflictFragment = rawExpr(onConflict)
+
+	return ths
+}
+
+// WithSchema binds schema to validate table, fields and on conflict target of generated query against.
+func (ths *createQueryBuilder) WithSchema(schema *Schema) *createQueryBuilder {
+	ths.schema = schema
+
+	return ths
+}
+
+// WithLint enables lint pass over raw fragments of generated query.
+func (ths *createQueryBuilder) WithLint(options LintOptions) *createQueryBuilder {
+	ths.lint = &options
+
+	return ths
+}
+
+// WithDialect sets sql dialect of generated query.
+func (ths *createQueryBuilder) WithDialect(dialect Dialect) *createQueryBuilder {
+	ths.options.dialect = dialect
+
+	return ths
+}
+
+// WithArrayBinding binds slice of IN (?) condition as single array argument using = ANY(?),
+// it requires postgres dialect.
+func (ths *createQueryBuilder) WithArrayBinding() *createQueryBuilder {
+	ths.options.arrayBinding = true
+
+	return ths
+}
+
+// WithFormat sets layout of generated query.
+func (ths *createQueryBuilder) WithFormat(format Format) *createQueryBuilder {
+	ths.options.format = format
+
+	return ths
+}
+
+// When calls apply with the builder only when condition is true, keeping the chain unbroken.
+func (ths *createQueryBuilder) When(condition bool, apply func(builder *createQueryBuilder)) *createQueryBuilder {
+	if condition {
+		apply(ths)
+	}
 
 	return ths
 }
 
 // BuildQuery generates final query string.
 func (ths *createQueryBuilder) BuildQuery() (string, error) {
+	query, _, err := ths.BuildQueryWithArgs()
+
+	return query, err
+}
+
+// BuildQueryWithArgs generates final query string and arguments bound to its placeholders.
+func (ths *createQueryBuilder) BuildQueryWithArgs() (string, []interface{}, error) {
+	expr, err := ths.buildExpr()
+	if err != nil {
+		return "", nil, err
+	}
+
+	return renderExpr(expr, ths.options)
+}
+
+// DebugString returns query with arguments inlined as escaped literals for logs, it must never be
+// executed.
+func (ths *createQueryBuilder) DebugString() string {
+	return debugString(ths)
+}
+
+func (ths *createQueryBuilder) buildExpr() (Expr, error) {
+	if ths.err != nil {
+		return Expr{}, ths.err
+	}
+	clauses := ths.clauses()
+	if err := checkFragments(clauses...); err != nil {
+		return Expr{}, err
+	}
+	if err := lint(ths.lint, false, clauses...); err != nil {
+		return Expr{}, err
+	}
 	if len(ths.intoFragment) == 0 {
-		return "", errors.New("no table specified for query")
+		return Expr{}, errors.New("no table specified for query")
 	}
 	if len(ths.fieldFragments) == 0 {
-		return "", errors.New("no field specified, add it using AddField method")
+		return Expr{}, errors.New("no field specified, add it using AddField method")
 	}
-	if len(ths.valueFragments) == 0 && len(ths.valueWithSelectFragment) == 0 {
-		return "", errors.New("no value(s) to be inserted, add it using AddValue or AddValueWithSelect method")
+	if len(ths.valueFragments) == 0 && len(ths.valueWithSelectFragment.SQL) == 0 {
+		return Expr{}, errors.New("no value(s) to be inserted, add it using AddValue or AddValueWithSelect method")
 	}
-	if len(ths.valueFragments) > 0 && len(ths.valueWithSelectFragment) > 0 {
-		return "", errors.New("use only AddValue or AddValueWithSelect to add value(s)")
+	if len(ths.valueFragments) > 0 && len(ths.valueWithSelectFragment.SQL) > 0 {
+		return Expr{}, errors.New("use only AddValue or AddValueWithSelect to add value(s)")
+	}
+	if ths.schema != nil {
+		if err := ths.validateSchema(); err != nil {
+			return Expr{}, err
+		}
 	}
 
-	queryFragments := []string{}
+	queryFragments := []Expr{}
 
 	if len(ths.cteFragments) > 0 {
-		queryFragments = append(queryFragments, fmt.Sprintf(
+		queryFragments = append(queryFragments, formatExpr(
 			"WITH %s",
-			strings.Join(ths.cteFragments, ", "),
+			joinExprs(ths.cteFragments, ", "),
 		))
 	}
 
-	queryFragments = append(queryFragments, fmt.Sprintf(
+	queryFragments = append(queryFragments, formatExpr(
 		"INSERT INTO %s (%s)",
-		ths.intoFragment,
-		strings.Join(ths.fieldFragments, ", "),
+		rawExpr(ths.intoFragment),
+		joinExprs(rawExprs(ths.fieldFragments), ", "),
 	))
 
 	if len(ths.valueFragments) > 0 {
-		queryFragments = append(queryFragments, fmt.Sprintf(
+		queryFragments = append(queryFragments, formatExpr(
 			"VALUES %s",
-			strings.Join(ths.valueFragments, ", "),
+			joinExprs(ths.valueFragments, ", "),
 		))
 	}
 
-	if len(ths.valueWithSelectFragment) > 0 {
+	if len(ths.valueWithSelectFragment.SQL) > 0 {
 		queryFragments = append(queryFragments, ths.valueWithSelectFragment)
 	}
 
-	if len(ths.onConflictFragment) > 0 {
+	if len(ths.onConflictFragment.SQL) > 0 {
 		queryFragments = append(queryFragments, ths.onConflictFragment)
 	}
 
-	query := strings.Join(queryFragments, " ")
-	query = whitespaceNormalizer.ReplaceAllString(query, " ")
+	return joinExprs(queryFragments, " "), nil
+}
+
+func (ths *createQueryBuilder) buildOptions() renderOptions {
+	return ths.options
+}
+
+func (ths *createQueryBuilder) shape() Shape {
+	return Shape{
+		Kind:       InsertStatement,
+		Table:      shapeTable(ths.intoFragment),
+		Conditions: []string{},
+	}
+}
+
+// clauses returns fragments of every clause to check.
+func (ths *createQueryBuilder) clauses() []clauseFragments {
+	clauses := []clauseFragments{
+		{clause: "cte", fragments: ths.cteFragments},
+		{clause: "table", fragments: tableFragments(ths.intoFragment)},
+		{clause: "field", fragments: rawExprs(ths.fieldFragments)},
+		{clause: "value", fragments: ths.valueFragments},
+	}
+
+	if len(ths.valueWithSelectFragment.SQL) > 0 {
+		clauses = append(clauses, clauseFragments{clause: "value with select", fragments: []Expr{ths.valueWithSelectFragment}})
+	}
+	if len(ths.onConflictFragment.SQL) > 0 {
+		clauses = append(clauses, clauseFragments{clause: "on conflict", fragments: []Expr{ths.onConflictFragment}})
+	}
+
+	return clauses
+}
+
+// validateSchema checks table, fields and on conflict target against bound schema.
+func (ths *createQueryBuilder) validateSchema() error {
+	scope := newSchemaScope(ths.schema)
+
+	if err := scope.addTable(ths.intoFragment); err != nil {
+		return err
+	}
+
+	if err := scope.validateColumns(ths.fieldFragments); err != nil {
+		return err
+	}
+
+	name, _ := splitTableAlias(ths.intoFragment)
+	if table, ok := ths.schema.Table(name); ok {
+		return validateOnConflict(table, ths.onConflictFragment.SQL)
+	}
 
-	return query, nil
+	return nil
 }
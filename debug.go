@@ -0,0 +1,158 @@
+package squbix
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// debugHeader marks output of DebugString, literals are escaped for reading only and the query
+// must never be executed.
+const debugHeader = "/* DEBUG ONLY, NOT FOR EXECUTION */ "
+
+var inlineMarker = regexp.MustCompile("\x00([0-9]+)\x00")
+
+// debugString returns query of builder with arguments inlined as literals of its dialect.
+func debugString(builder Builder) string {
+	expr, err := builder.buildExpr()
+	if err != nil {
+		return debugHeader + debugError(err)
+	}
+
+	options := builder.buildOptions()
+	options.inline = true
+
+	query, _, err := renderExpr(expr, options)
+	if err != nil {
+		return debugHeader + debugError(err)
+	}
+
+	return debugHeader + query
+}
+
+func debugError(err error) string {
+	return fmt.Sprintf("/* invalid query: %s */", strings.Replace(err.Error(), "*/", "* /", -1))
+}
+
+// inlineMarkerOf returns marker standing for argument at 1-based position until inlineArgs
+// replaces it, it survives whitespace normalization unlike literals with line breaks.
+func inlineMarkerOf(position int) string {
+	return fmt.Sprintf("\x00%d\x00", position)
+}
+
+// inlineArgs replaces argument markers of query with literals of the dialect.
+func inlineArgs(query string, args []interface{}, dialect Dialect) string {
+	return inlineMarker.ReplaceAllStringFunc(query, func(marker string) string {
+		position, _ := strconv.Atoi(strings.Trim(marker, "\x00"))
+
+		return literal(args[position-1], dialect)
+	})
+}
+
+// literal returns value as sql literal of the dialect.
+func literal(value interface{}, dialect Dialect) string {
+	if reflected := reflect.ValueOf(value); reflected.Kind() == reflect.Ptr && reflected.IsNil() {
+		return "NULL"
+	}
+
+	if valuer, ok := value.(driver.Valuer); ok {
+		converted, err := valuer.Value()
+		if err != nil {
+			return debugError(err)
+		}
+
+		value = converted
+	}
+
+	switch typed := value.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return stringLiteral(typed, dialect)
+	case []byte:
+		if typed == nil {
+			return "NULL"
+		}
+
+		return bytesLiteral(typed, dialect)
+	case bool:
+		if typed {
+			return "TRUE"
+		}
+
+		return "FALSE"
+	case time.Time:
+		return timeLiteral(typed, dialect)
+	case float32:
+		return strconv.FormatFloat(float64(typed), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(typed, 'g', -1, 64)
+	}
+
+	reflected := reflect.ValueOf(value)
+
+	switch reflected.Kind() {
+	case reflect.Ptr:
+		return literal(reflected.Elem().Interface(), dialect)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(reflected.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(reflected.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(reflected.Float(), 'g', -1, 64)
+	case reflect.Bool:
+		return literal(reflected.Bool(), dialect)
+	case reflect.String:
+		return stringLiteral(reflected.String(), dialect)
+	case reflect.Slice, reflect.Array:
+		if reflected.Kind() == reflect.Slice && reflected.Type().Elem().Kind() == reflect.Uint8 {
+			return bytesLiteral(reflected.Bytes(), dialect)
+		}
+
+		elements := make([]string, 0, reflected.Len())
+		for index := 0; index < reflected.Len(); index++ {
+			elements = append(elements, literal(reflected.Index(index).Interface(), dialect))
+		}
+
+		if dialect.allows(Postgres) {
+			return fmt.Sprintf("ARRAY[%s]", strings.Join(elements, ", "))
+		}
+
+		return fmt.Sprintf("(%s)", strings.Join(elements, ", "))
+	}
+
+	return stringLiteral(fmt.Sprint(value), dialect)
+}
+
+// stringLiteral quotes text doubling single quotes, mysql also treats backslash as escape.
+func stringLiteral(text string, dialect Dialect) string {
+	text = strings.Replace(text, "'", "''", -1)
+	if dialect == MySQL {
+		text = strings.Replace(text, `\`, `\\`, -1)
+	}
+
+	return "'" + text + "'"
+}
+
+func bytesLiteral(data []byte, dialect Dialect) string {
+	if dialect == Postgres {
+		return fmt.Sprintf(`'\x%s'::bytea`, hex.EncodeToString(data))
+	}
+
+	return fmt.Sprintf("X'%s'", strings.ToUpper(hex.EncodeToString(data)))
+}
+
+// timeLiteral returns ISO timestamp, mysql and sqlite get UTC time without zone as they don't
+// parse zone offsets.
+func timeLiteral(value time.Time, dialect Dialect) string {
+	if dialect == MySQL || dialect == SQLite {
+		return "'" + value.UTC().Format("2006-01-02 15:04:05.999999") + "'"
+	}
+
+	return "'" + value.Format(time.RFC3339Nano) + "'"
+}
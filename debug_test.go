@@ -0,0 +1,67 @@
+package squbix
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type failingValuer struct{}
+
+func (ths failingValuer) Value() (driver.Value, error) {
+	return nil, errors.New("no value")
+}
+
+func TestDebugString(t *testing.T) {
+	Convey("Given query with arguments of every kind", t, func() {
+		createdAt := time.Date(2021, 1, 27, 10, 30, 0, 500000000, time.FixedZone("WIB", 7*60*60))
+		var missing *string
+
+		build := func(dialect Dialect) string {
+			return NewUpdateQuery("files").
+				WithDialect(dialect).
+				AddSetFieldExpr(
+					NewExpr("name = ?", `it's C:\tmp`),
+					NewExpr("content = ?", []byte{0xde, 0xad}),
+					NewExpr("created_at = ?", createdAt),
+					NewExpr("note = ?", missing),
+					NewExpr("size = ?", 1.5),
+					NewExpr("public = ?", true),
+				).
+				AddWhereExpr(NewExpr("id IN (?) AND version = ?", []int64{1, 2}, uint8(3))).
+				DebugString()
+		}
+
+		Convey("It should inline escaped literals of the dialect", func() {
+			So(build(Postgres), ShouldEqual, `/* DEBUG ONLY, NOT FOR EXECUTION */ UPDATE files SET name = 'it''s C:\tmp', content = '\xdead'::bytea, created_at = '2021-01-27T10:30:00.5+07:00', note = NULL, size = 1.5, public = TRUE WHERE id IN (1, 2) AND version = 3`)
+			So(build(MySQL), ShouldEqual, `/* DEBUG ONLY, NOT FOR EXECUTION */ UPDATE files SET name = 'it''s C:\\tmp', content = X'DEAD', created_at = '2021-01-27 03:30:00.5', note = NULL, size = 1.5, public = TRUE WHERE id IN (1, 2) AND version = 3`)
+			So(build(SQLite), ShouldEqual, `/* DEBUG ONLY, NOT FOR EXECUTION */ UPDATE files SET name = 'it''s C:\tmp', content = X'DEAD', created_at = '2021-01-27 03:30:00.5', note = NULL, size = 1.5, public = TRUE WHERE id IN (1, 2) AND version = 3`)
+		})
+	})
+
+	Convey("Given string argument with line breaks and postgres array binding", t, func() {
+		debug := NewReadQuery("notes").
+			WithDialect(Postgres).
+			WithArrayBinding().
+			AddSelect("id").
+			AddWhereExpr(NewExpr("body = ? AND tag_id IN (?)", "line 1\n  line 2", []int{7, 8})).
+			DebugString()
+
+		Convey("It should keep the literal as is", func() {
+			So(debug, ShouldEqual, "/* DEBUG ONLY, NOT FOR EXECUTION */ SELECT id FROM notes WHERE body = 'line 1\n  line 2' AND tag_id = ANY(ARRAY[7, 8])")
+		})
+	})
+
+	Convey("Given invalid query and failing argument", t, func() {
+		invalid := NewReadQuery("notes").DebugString()
+		failing := NewDeleteQuery("notes").AddWhereExpr(NewExpr("id = ?", failingValuer{})).DebugString()
+
+		Convey("It should report the error in comment", func() {
+			So(invalid, ShouldEqual, "/* DEBUG ONLY, NOT FOR EXECUTION */ /* invalid query: no field selected, add it using AddSelect method */")
+			So(failing, ShouldEqual, "/* DEBUG ONLY, NOT FOR EXECUTION */ DELETE FROM notes WHERE id = /* invalid query: no value */")
+		})
+	})
+}
@@ -3,14 +3,22 @@ package squbix
 import (
 	"errors"
 	"fmt"
-	"strings"
 )
 
 type deleteQueryBuilder struct {
 	fromFragment   string
-	whereFragments []string
+	whereFragments []Expr
+	hardDelete     bool
+	schema         *Schema
+	lint           *LintOptions
+	rawConditions  bool
+	options        renderOptions
+	err            error
 }
 
+// DeleteQueryBuilder is sql builder for delete operation, it allows the builder to be referred from other packages.
+type DeleteQueryBuilder = deleteQueryBuilder
+
 // NewDeleteQuery creates new sql builder instance for delete operation.
 func NewDeleteQuery(table string) *deleteQueryBuilder {
 	return &deleteQueryBuilder{
@@ -20,33 +28,205 @@ func NewDeleteQuery(table string) *deleteQueryBuilder {
 
 // AddWhere adds where clause in generated query.
 func (ths *deleteQueryBuilder) AddWhere(where ...string) *deleteQueryBuilder {
+	ths.whereFragments = append(ths.whereFragments, rawExprs(where)...)
+
+	return ths
+}
+
+// AddWhereExpr adds where clause with bound arguments in generated query.
+func (ths *deleteQueryBuilder) AddWhereExpr(where ...Expr) *deleteQueryBuilder {
 	ths.whereFragments = append(ths.whereFragments, where...)
 
 	return ths
 }
 
+// HardDelete removes rows even if table is configured for soft delete.
+func (ths *deleteQueryBuilder) HardDelete() *deleteQueryBuilder {
+	ths.hardDelete = true
+
+	return ths
+}
+
+// WithSchema binds schema to validate table of generated query against.
+func (ths *deleteQueryBuilder) WithSchema(schema *Schema) *deleteQueryBuilder {
+	ths.schema = schema
+
+	return ths
+}
+
+// WithoutAutoParenthesis joins where fragments as they are, by default fragments having top-level
+// OR are wrapped in parentheses so they don't weaken conditions they are joined with using AND.
+func (ths *deleteQueryBuilder) WithoutAutoParenthesis() *deleteQueryBuilder {
+	ths.rawConditions = true
+
+	return ths
+}
+
+// WithLint enables lint pass over raw fragments of generated query.
+func (ths *deleteQueryBuilder) WithLint(options LintOptions) *deleteQueryBuilder {
+	ths.lint = &options
+
+	return ths
+}
+
+// WithDialect sets sql dialect of generated query.
+func (ths *deleteQueryBuilder) WithDialect(dialect Dialect) *deleteQueryBuilder {
+	ths.options.dialect = dialect
+
+	return ths
+}
+
+// WithArrayBinding binds slice of IN (?) condition as single array argument using = ANY(?),
+// it requires postgres dialect.
+func (ths *deleteQueryBuilder) WithArrayBinding() *deleteQueryBuilder {
+	ths.options.arrayBinding = true
+
+	return ths
+}
+
+// WithFormat sets layout of generated query.
+func (ths *deleteQueryBuilder) WithFormat(format Format) *deleteQueryBuilder {
+	ths.options.format = format
+
+	return ths
+}
+
+// When calls apply with the builder only when condition is true, keeping the chain unbroken.
+func (ths *deleteQueryBuilder) When(condition bool, apply func(builder *deleteQueryBuilder)) *deleteQueryBuilder {
+	if condition {
+		apply(ths)
+	}
+
+	return ths
+}
+
+// AddWhereIf adds where clause in generated query only when condition is true.
+func (ths *deleteQueryBuilder) AddWhereIf(condition bool, where ...string) *deleteQueryBuilder {
+	if condition {
+		ths.AddWhere(where...)
+	}
+
+	return ths
+}
+
+// AddWhereIfNotZero adds where clause binding value to its placeholders only when value is not
+// nil nor zero value of its type.
+func (ths *deleteQueryBuilder) AddWhereIfNotZero(value interface{}, where string) *deleteQueryBuilder {
+	if !isZeroValue(value) {
+		ths.AddWhereExpr(bindEach(where, value))
+	}
+
+	return ths
+}
+
+// AddWhereFilter adds where clause for each field of filter struct tagged with sq, e.g.
+// `sq:"status = ?"`, skipping nil and zero value fields.
+func (ths *deleteQueryBuilder) AddWhereFilter(filter interface{}) *deleteQueryBuilder {
+	conditions, err := filterConditions(filter)
+	if err != nil {
+		ths.err = err
+
+		return ths
+	}
+
+	return ths.AddWhereExpr(conditions...)
+}
+
 // BuildQuery generates final query string.
 func (ths *deleteQueryBuilder) BuildQuery() (string, error) {
+	query, _, err := ths.BuildQueryWithArgs()
+
+	return query, err
+}
+
+// BuildQueryWithArgs generates final query string and arguments bound to its placeholders.
+func (ths *deleteQueryBuilder) BuildQueryWithArgs() (string, []interface{}, error) {
+	expr, err := ths.buildExpr()
+	if err != nil {
+		return "", nil, err
+	}
+
+	return renderExpr(expr, ths.options)
+}
+
+// DebugString returns query with arguments inlined as escaped literals for logs, it must never be
+// executed.
+func (ths *deleteQueryBuilder) DebugString() string {
+	return debugString(ths)
+}
+
+func (ths *deleteQueryBuilder) buildExpr() (Expr, error) {
+	if ths.err != nil {
+		return Expr{}, ths.err
+	}
+	clauses := []clauseFragments{
+		{clause: "table", fragments: tableFragments(ths.fromFragment)},
+		{clause: "where", fragments: ths.whereFragments},
+	}
+	if err := checkFragments(clauses...); err != nil {
+		return Expr{}, err
+	}
+	if err := lint(ths.lint, !ths.rawConditions, clauses...); err != nil {
+		return Expr{}, err
+	}
 	if len(ths.fromFragment) == 0 {
-		return "", errors.New("no table specified for query")
+		return Expr{}, errors.New("no table specified for query")
+	}
+	if ths.schema != nil {
+		if err := newSchemaScope(ths.schema).addTable(ths.fromFragment); err != nil {
+			return Expr{}, err
+		}
 	}
 
-	queryFragments := []string{}
+	queryFragments := []Expr{}
+	conditions := []Expr{}
 
-	queryFragments = append(queryFragments, fmt.Sprintf(
-		"DELETE FROM %s",
-		ths.fromFragment,
-	))
+	table, _ := splitTableAlias(ths.fromFragment)
+	softDelete, isSoftDelete := lookupSoftDelete(table)
 
-	if len(ths.whereFragments) > 0 {
-		queryFragments = append(queryFragments, fmt.Sprintf(
+	if isSoftDelete && !ths.hardDelete {
+		// rows deleted before keep the time they were deleted at.
+		conditions = append(conditions, rawExpr(softDelete.Column+" IS NULL"))
+
+		queryFragments = append(queryFragments, rawExpr(fmt.Sprintf(
+			"UPDATE %s SET %s = %s",
+			ths.fromFragment,
+			softDelete.Column,
+			softDelete.Value,
+		)))
+	} else {
+		queryFragments = append(queryFragments, rawExpr(fmt.Sprintf(
+			"DELETE FROM %s",
+			ths.fromFragment,
+		)))
+	}
+
+	whereFragments := ths.whereFragments
+
+	if !ths.rawConditions {
+		whereFragments = parenthesizeOr(whereFragments, ths.options.dialect)
+	}
+
+	whereFragments = guardConditions(whereFragments, conditions, ths.options.dialect)
+
+	if len(whereFragments) > 0 {
+		queryFragments = append(queryFragments, formatExpr(
 			"WHERE %s",
-			strings.Join(ths.whereFragments, " AND "),
+			joinExprs(whereFragments, " AND "),
 		))
 	}
 
-	query := strings.Join(queryFragments, " ")
-	query = whitespaceNormalizer.ReplaceAllString(query, " ")
+	return joinExprs(queryFragments, " "), nil
+}
+
+func (ths *deleteQueryBuilder) buildOptions() renderOptions {
+	return ths.options
+}
 
-	return query, nil
+func (ths *deleteQueryBuilder) shape() Shape {
+	return Shape{
+		Kind:       DeleteStatement,
+		Table:      shapeTable(ths.fromFragment),
+		Conditions: shapeConditions(ths.whereFragments),
+	}
 }
@@ -31,4 +31,21 @@ func TestNewDeleteQuery(t *testing.T) {
 			So(query, ShouldEqual, "DELETE FROM table_a WHERE table_a.id = :id AND table_a.name = :name")
 		})
 	})
+
+	Convey("Given where fragments with top-level OR", t, func() {
+		query, err := NewDeleteQuery("table_a").
+			AddWhere("field_a IS NULL", "field_b = 1 or field_b = 2 -- legacy rows").
+			BuildQuery()
+		raw, rawErr := NewDeleteQuery("table_a").
+			WithoutAutoParenthesis().
+			AddWhere("field_a IS NULL", "field_b = 1 OR field_b = 2").
+			BuildQuery()
+
+		Convey("It should wrap them in parentheses unless disabled", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "DELETE FROM table_a WHERE field_a IS NULL AND (field_b = 1 or field_b = 2 -- legacy rows\n)")
+			So(rawErr, ShouldBeNil)
+			So(raw, ShouldEqual, "DELETE FROM table_a WHERE field_a IS NULL AND field_b = 1 OR field_b = 2")
+		})
+	})
 }
@@ -0,0 +1,67 @@
+package squbix
+
+import (
+	"fmt"
+)
+
+// Dialect identifies SQL flavor of generated query, it decides placeholder style and which vendor
+// specific features are allowed.
+type Dialect int
+
+const (
+	// Standard generates ? placeholders and leaves vendor specific features unchecked, it is the
+	// default dialect of every builder.
+	Standard Dialect = iota
+	// Postgres generates $1, $2, ... placeholders.
+	Postgres
+	// MySQL generates ? placeholders.
+	MySQL
+	// SQLite generates ? placeholders.
+	SQLite
+)
+
+// String returns name of the dialect.
+func (ths Dialect) String() string {
+	switch ths {
+	case Standard:
+		return "standard"
+	case Postgres:
+		return "postgres"
+	case MySQL:
+		return "mysql"
+	case SQLite:
+		return "sqlite"
+	}
+
+	return fmt.Sprintf("dialect(%d)", int(ths))
+}
+
+// placeholder returns placeholder of argument at 1-based position.
+func (ths Dialect) placeholder(position int) string {
+	if ths == Postgres {
+		return fmt.Sprintf("$%d", position)
+	}
+
+	return string(placeholder)
+}
+
+// allows reports whether feature available only on given dialects may be used, standard dialect
+// allows every feature.
+func (ths Dialect) allows(dialects ...Dialect) bool {
+	if ths == Standard {
+		return true
+	}
+
+	for _, dialect := range dialects {
+		if ths == dialect {
+			return true
+		}
+	}
+
+	return false
+}
+
+// unsupported returns error telling feature is not supported by the dialect.
+func (ths Dialect) unsupported(feature string) error {
+	return fmt.Errorf("%s is not supported by %s dialect", feature, ths)
+}
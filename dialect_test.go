@@ -0,0 +1,54 @@
+package squbix
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDialect(t *testing.T) {
+	Convey("Given query built with each dialect", t, func() {
+		build := func(dialect Dialect) string {
+			query, _, err := NewUpdateQuery("table_a").
+				WithDialect(dialect).
+				AddSetFieldExpr(NewExpr("field_a = ?", "a")).
+				AddWhereExpr(NewExpr("id = ? AND data ?? 'key'", 1)).
+				BuildQueryWithArgs()
+			So(err, ShouldBeNil)
+
+			return query
+		}
+
+		Convey("It should use placeholders of the dialect", func() {
+			So(build(Standard), ShouldEqual, "UPDATE table_a SET field_a = ? WHERE id = ? AND data ? 'key'")
+			So(build(Postgres), ShouldEqual, "UPDATE table_a SET field_a = $1 WHERE id = $2 AND data ? 'key'")
+			So(build(MySQL), ShouldEqual, "UPDATE table_a SET field_a = ? WHERE id = ? AND data ? 'key'")
+			So(build(SQLite), ShouldEqual, "UPDATE table_a SET field_a = ? WHERE id = ? AND data ? 'key'")
+		})
+	})
+
+	Convey("Given dialect names", t, func() {
+		Convey("It should returns name of each dialect", func() {
+			So(Standard.String(), ShouldEqual, "standard")
+			So(Postgres.String(), ShouldEqual, "postgres")
+			So(MySQL.String(), ShouldEqual, "mysql")
+			So(SQLite.String(), ShouldEqual, "sqlite")
+			So(Dialect(9).String(), ShouldEqual, "dialect(9)")
+		})
+	})
+
+	Convey("Given array binding on dialect without arrays", t, func() {
+		query, err := NewReadQuery("table_a").
+			WithDialect(MySQL).
+			WithArrayBinding().
+			AddSelect("field_a").
+			AddWhereExpr(NewExpr("id IN (?)", []int{1})).
+			BuildQuery()
+
+		Convey("It should returns error", func() {
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "array binding is not supported by mysql dialect")
+			So(query, ShouldEqual, "")
+		})
+	})
+}
@@ -0,0 +1,75 @@
+package squbix
+
+import (
+	"fmt"
+	"strings"
+)
+
+// validateDistinctOn checks DISTINCT ON expressions match leftmost ORDER BY expressions, the rule
+// postgres enforces: once order by reaches expression other than distinct on ones, no distinct on
+// expression may follow it.
+func validateDistinctOn(distinctOn []Expr, orderBy []Expr) error {
+	distinctItems, err := listItems(exprSQLs(distinctOn))
+	if err != nil {
+		return err
+	}
+
+	orderItems, err := listItems(exprSQLs(orderBy))
+	if err != nil {
+		return err
+	}
+
+	distinct := map[string]bool{}
+	for _, item := range distinctItems {
+		distinct[item] = true
+	}
+
+	other := ""
+	for _, item := range orderItems {
+		item = orderDirectionPattern.ReplaceAllString(item, "")
+
+		switch {
+		case !distinct[item] && len(other) == 0:
+			other = item
+		case distinct[item] && len(other) > 0:
+			return fmt.Errorf("distinct on expression %q must come before order by expression %q", item, other)
+		}
+	}
+
+	return nil
+}
+
+// listItems splits comma separated fragments into items with normalized whitespace, commas
+// nested in parentheses are kept.
+func listItems(fragments []string) ([]string, error) {
+	items := []string{}
+
+	for _, fragment := range fragments {
+		tokens, err := tokenize(fragment)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, part := range splitTokens(withoutComments(tokens), ",") {
+			if len(part) == 0 {
+				continue
+			}
+
+			item := fragment[part[0].start:part[len(part)-1].end]
+			items = append(items, strings.TrimSpace(whitespaceNormalizer.ReplaceAllString(item, " ")))
+		}
+	}
+
+	return items, nil
+}
+
+func withoutComments(tokens []token) []token {
+	kept := make([]token, 0, len(tokens))
+	for _, current := range tokens {
+		if current.kind != tokenComment {
+			kept = append(kept, current)
+		}
+	}
+
+	return kept
+}
@@ -0,0 +1,82 @@
+package squbix
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDistinct(t *testing.T) {
+	Convey("Given distinct read query", t, func() {
+		query, err := NewReadQuery("orders").
+			AddSelect("customer_id", "status").
+			Distinct().
+			AddOrderBy("customer_id").
+			BuildQuery()
+
+		Convey("It should returns generated query", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT DISTINCT customer_id, status FROM orders ORDER BY customer_id")
+		})
+	})
+
+	Convey("Given distinct on matching leftmost order by", t, func() {
+		query, err := NewReadQuery("orders").
+			WithDialect(Postgres).
+			AddSelect("customer_id", "id", "created_at").
+			DistinctOn("customer_id", "lower(status)").
+			AddOrderBy("lower(status) ASC,  customer_id", "created_at DESC NULLS LAST").
+			BuildQuery()
+
+		Convey("It should returns generated query", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT DISTINCT ON (customer_id, lower(status)) customer_id, id, created_at FROM orders ORDER BY lower(status) ASC, customer_id, created_at DESC NULLS LAST")
+		})
+	})
+
+	Convey("Given distinct on without order by", t, func() {
+		query, err := NewReadQuery("orders").
+			AddSelect("customer_id").
+			DistinctOn("customer_id").
+			BuildQuery()
+
+		Convey("It should returns generated query", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT DISTINCT ON (customer_id) customer_id FROM orders")
+		})
+	})
+
+	Convey("Given distinct on not matching leftmost order by", t, func() {
+		query, err := NewReadQuery("orders").
+			AddSelect("customer_id", "id").
+			DistinctOn("customer_id").
+			AddOrderBy("created_at DESC", "customer_id").
+			BuildQuery()
+
+		Convey("It should returns error", func() {
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, `distinct on expression "customer_id" must come before order by expression "created_at"`)
+			So(query, ShouldEqual, "")
+		})
+	})
+
+	Convey("Given distinct on with dialect without it", t, func() {
+		_, mysqlErr := NewReadQuery("orders").
+			WithDialect(MySQL).
+			AddSelect("customer_id").
+			DistinctOn("customer_id").
+			BuildQuery()
+		_, bothErr := NewReadQuery("orders").
+			AddSelect("customer_id").
+			Distinct().
+			DistinctOn("customer_id").
+			BuildQuery()
+
+		Convey("It should returns error", func() {
+			So(mysqlErr, ShouldNotBeNil)
+			So(mysqlErr.Error(), ShouldEqual, "DISTINCT ON is not supported by mysql dialect")
+			So(bothErr, ShouldNotBeNil)
+			So(bothErr.Error(), ShouldEqual, "distinct and distinct on can't be used together")
+		})
+	})
+}
@@ -0,0 +1,64 @@
+package squbix
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Rows is result set of query, *sql.Rows implements it.
+type Rows interface {
+	Columns() ([]string, error)
+	Next() bool
+	Scan(dest ...interface{}) error
+	Err() error
+	Close() error
+}
+
+// Executor executes queries generated by builders, application code depending on it can be tested
+// with squbixmock instead of database.
+type Executor interface {
+	Exec(ctx context.Context, builder Builder) (sql.Result, error)
+	Query(ctx context.Context, builder Builder) (Rows, error)
+}
+
+// SQLRunner runs sql queries, *sql.DB, *sql.Tx and *sql.Conn implement it.
+type SQLRunner interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+type executor struct {
+	runner SQLRunner
+}
+
+// NewExecutor creates executor running queries generated by builders on runner.
+func NewExecutor(runner SQLRunner) Executor {
+	return &executor{
+		runner: runner,
+	}
+}
+
+// Exec executes query of builder without returning rows.
+func (ths *executor) Exec(ctx context.Context, builder Builder) (sql.Result, error) {
+	query, args, err := builder.BuildQueryWithArgs()
+	if err != nil {
+		return nil, err
+	}
+
+	return ths.runner.ExecContext(ctx, query, args...)
+}
+
+// Query executes query of builder returning its rows.
+func (ths *executor) Query(ctx context.Context, builder Builder) (Rows, error) {
+	query, args, err := builder.BuildQueryWithArgs()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := ths.runner.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
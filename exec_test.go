@@ -0,0 +1,66 @@
+package squbix
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var _ SQLRunner = (*sql.DB)(nil)
+var _ SQLRunner = (*sql.Tx)(nil)
+var _ SQLRunner = (*sql.Conn)(nil)
+var _ Rows = (*sql.Rows)(nil)
+
+type recordingRunner struct {
+	query string
+	args  []interface{}
+	err   error
+}
+
+func (ths *recordingRunner) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ths.query = query
+	ths.args = args
+
+	return nil, ths.err
+}
+
+func (ths *recordingRunner) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ths.query = query
+	ths.args = args
+
+	return nil, ths.err
+}
+
+func TestExecutor(t *testing.T) {
+	Convey("Given executor over sql runner", t, func() {
+		runner := &recordingRunner{}
+		executor := NewExecutor(runner)
+
+		_, err := executor.Exec(context.Background(), NewDeleteQuery("orders").
+			WithDialect(Postgres).
+			AddWhereExpr(NewExpr("id = ?", 1)))
+
+		Convey("It should run generated query with its arguments", func() {
+			So(err, ShouldBeNil)
+			So(runner.query, ShouldEqual, "DELETE FROM orders WHERE id = $1")
+			So(runner.args, ShouldResemble, []interface{}{1})
+		})
+	})
+
+	Convey("Given failing query or builder", t, func() {
+		failure := errors.New("connection reset")
+		executor := NewExecutor(&recordingRunner{err: failure})
+
+		rows, queryErr := executor.Query(context.Background(), NewReadQuery("orders").AddSelect("id"))
+		_, buildErr := executor.Exec(context.Background(), NewUpdateQuery("orders"))
+
+		Convey("It should returns the error", func() {
+			So(queryErr, ShouldEqual, failure)
+			So(rows, ShouldBeNil)
+			So(buildErr, ShouldNotBeNil)
+		})
+	})
+}
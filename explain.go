@@ -0,0 +1,207 @@
+package squbix
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ExplainFormat is output format of EXPLAIN statement.
+type ExplainFormat string
+
+const (
+	// ExplainText outputs plan as text, it is the database default.
+	ExplainText ExplainFormat = "TEXT"
+	// ExplainJSON outputs plan as json, postgres plan in this format is parsed by ParsePostgresPlan.
+	ExplainJSON ExplainFormat = "JSON"
+)
+
+// ExplainOptions configures EXPLAIN statement generated by Explain.
+type ExplainOptions struct {
+	// Analyze executes the query and reports actual row counts and timing.
+	Analyze bool
+	// Verbose reports output columns and schema qualified names, postgres only.
+	Verbose bool
+	// Buffers reports buffer usage, postgres only.
+	Buffers bool
+	// Format of the plan, empty uses the database default.
+	Format ExplainFormat
+}
+
+// Explain derives EXPLAIN statement of the query generated by builder in the builder dialect:
+// EXPLAIN (...) on postgres, EXPLAIN [ANALYZE | FORMAT=...] on mysql and EXPLAIN QUERY PLAN on
+// sqlite. Analyze executes the query, wrap data modifying queries in rolled back transaction.
+func Explain(builder Builder, options ExplainOptions) *derivedQueryBuilder {
+	dialect := builder.buildOptions().dialect
+
+	return &derivedQueryBuilder{
+		build: func() (Expr, error) {
+			prefix, err := explainPrefix(dialect, options)
+			if err != nil {
+				return Expr{}, err
+			}
+
+			expr, err := builder.buildExpr()
+			if err != nil {
+				return Expr{}, err
+			}
+
+			return formatExpr("%s %s", rawExpr(prefix), expr), nil
+		},
+		options: builder.buildOptions(),
+		source:  builder,
+	}
+}
+
+func explainPrefix(dialect Dialect, options ExplainOptions) (string, error) {
+	switch dialect {
+	case MySQL:
+		if options.Verbose || options.Buffers {
+			return "", dialect.unsupported("EXPLAIN VERBOSE and BUFFERS")
+		}
+		if options.Analyze && len(options.Format) > 0 {
+			return "", errors.New("mysql EXPLAIN ANALYZE has no format option")
+		}
+		if options.Analyze {
+			return "EXPLAIN ANALYZE", nil
+		}
+		if len(options.Format) > 0 {
+			return fmt.Sprintf("EXPLAIN FORMAT=%s", options.Format), nil
+		}
+
+		return "EXPLAIN", nil
+	case SQLite:
+		if options != (ExplainOptions{}) {
+			return "", dialect.unsupported("EXPLAIN option")
+		}
+
+		return "EXPLAIN QUERY PLAN", nil
+	}
+
+	fragments := []string{}
+
+	if options.Analyze {
+		fragments = append(fragments, "ANALYZE")
+	}
+
+	if options.Verbose {
+		fragments = append(fragments, "VERBOSE")
+	}
+
+	if options.Buffers {
+		fragments = append(fragments, "BUFFERS")
+	}
+
+	if len(options.Format) > 0 {
+		fragments = append(fragments, fmt.Sprintf("FORMAT %s", options.Format))
+	}
+
+	if len(fragments) == 0 {
+		return "EXPLAIN", nil
+	}
+
+	return fmt.Sprintf("EXPLAIN (%s)", strings.Join(fragments, ", ")), nil
+}
+
+// Plan is postgres query plan parsed from output of EXPLAIN (FORMAT JSON).
+type Plan struct {
+	Root          PlanNode `json:"Plan"`
+	PlanningTime  float64  `json:"Planning Time"`
+	ExecutionTime float64  `json:"Execution Time"`
+}
+
+// PlanNode is single node of postgres query plan, actual fields are filled only by EXPLAIN
+// ANALYZE.
+type PlanNode struct {
+	NodeType        string     `json:"Node Type"`
+	RelationName    string     `json:"Relation Name"`
+	Alias           string     `json:"Alias"`
+	IndexName       string     `json:"Index Name"`
+	StartupCost     float64    `json:"Startup Cost"`
+	TotalCost       float64    `json:"Total Cost"`
+	PlanRows        float64    `json:"Plan Rows"`
+	PlanWidth       int        `json:"Plan Width"`
+	ActualRows      float64    `json:"Actual Rows"`
+	ActualLoops     float64    `json:"Actual Loops"`
+	ActualTotalTime float64    `json:"Actual Total Time"`
+	Filter          string     `json:"Filter"`
+	IndexCond       string     `json:"Index Cond"`
+	Plans           []PlanNode `json:"Plans"`
+}
+
+// ParsePostgresPlan parses output of postgres EXPLAIN (FORMAT JSON).
+func ParsePostgresPlan(data []byte) (*Plan, error) {
+	plans := []Plan{}
+	if err := json.Unmarshal(data, &plans); err != nil {
+		return nil, fmt.Errorf("invalid postgres plan: %s", err)
+	}
+
+	if len(plans) != 1 {
+		return nil, fmt.Errorf("invalid postgres plan: expected 1 plan, got %d", len(plans))
+	}
+
+	return &plans[0], nil
+}
+
+// TotalCost returns estimated total cost of the query.
+func (ths *Plan) TotalCost() float64 {
+	return ths.Root.TotalCost
+}
+
+// EstimatedRows returns estimated number of rows the query returns.
+func (ths *Plan) EstimatedRows() float64 {
+	return ths.Root.PlanRows
+}
+
+// Nodes returns every node of the plan in depth first order.
+func (ths *Plan) Nodes() []PlanNode {
+	nodes := []PlanNode{}
+
+	var walk func(node PlanNode)
+	walk = func(node PlanNode) {
+		nodes = append(nodes, node)
+		for _, child := range node.Plans {
+			walk(child)
+		}
+	}
+
+	walk(ths.Root)
+
+	return nodes
+}
+
+// SeqScans returns sequential scan nodes of the plan.
+func (ths *Plan) SeqScans() []PlanNode {
+	scans := []PlanNode{}
+	for _, node := range ths.Nodes() {
+		if node.NodeType == "Seq Scan" {
+			scans = append(scans, node)
+		}
+	}
+
+	return scans
+}
+
+// IndexScans returns nodes of the plan scanning an index.
+func (ths *Plan) IndexScans() []PlanNode {
+	scans := []PlanNode{}
+	for _, node := range ths.Nodes() {
+		if len(node.IndexName) > 0 {
+			scans = append(scans, node)
+		}
+	}
+
+	return scans
+}
+
+// UsesIndex reports whether the plan scans index of the name, empty name matches any index.
+func (ths *Plan) UsesIndex(name string) bool {
+	for _, node := range ths.IndexScans() {
+		if len(name) == 0 || node.IndexName == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,90 @@
+package squbix
+
+import (
+	"io/ioutil"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestExplain(t *testing.T) {
+	Convey("Given read query explained in each dialect", t, func() {
+		explain := func(dialect Dialect, options ExplainOptions) (string, []interface{}, error) {
+			return Explain(
+				NewReadQuery("orders").
+					WithDialect(dialect).
+					AddSelect("id").
+					AddWhereExpr(NewExpr("status = ?", "paid")),
+				options,
+			).BuildQueryWithArgs()
+		}
+
+		postgres, postgresArgs, postgresErr := explain(Postgres, ExplainOptions{Analyze: true, Buffers: true, Format: ExplainJSON})
+		standard, _, standardErr := explain(Standard, ExplainOptions{})
+		mysql, _, mysqlErr := explain(MySQL, ExplainOptions{Format: ExplainJSON})
+		mysqlAnalyze, _, mysqlAnalyzeErr := explain(MySQL, ExplainOptions{Analyze: true})
+		sqlite, _, sqliteErr := explain(SQLite, ExplainOptions{})
+
+		Convey("It should returns explain statement of the dialect", func() {
+			So(postgresErr, ShouldBeNil)
+			So(postgres, ShouldEqual, "EXPLAIN (ANALYZE, BUFFERS, FORMAT JSON) SELECT id FROM orders WHERE status = $1")
+			So(postgresArgs, ShouldResemble, []interface{}{"paid"})
+			So(standardErr, ShouldBeNil)
+			So(standard, ShouldEqual, "EXPLAIN SELECT id FROM orders WHERE status = ?")
+			So(mysqlErr, ShouldBeNil)
+			So(mysql, ShouldEqual, "EXPLAIN FORMAT=JSON SELECT id FROM orders WHERE status = ?")
+			So(mysqlAnalyzeErr, ShouldBeNil)
+			So(mysqlAnalyze, ShouldEqual, "EXPLAIN ANALYZE SELECT id FROM orders WHERE status = ?")
+			So(sqliteErr, ShouldBeNil)
+			So(sqlite, ShouldEqual, "EXPLAIN QUERY PLAN SELECT id FROM orders WHERE status = ?")
+		})
+	})
+
+	Convey("Given explain options the dialect lacks", t, func() {
+		_, mysqlErr := Explain(NewDeleteQuery("orders").WithDialect(MySQL).AddWhere("id = 1"), ExplainOptions{Buffers: true}).BuildQuery()
+		_, sqliteErr := Explain(NewDeleteQuery("orders").WithDialect(SQLite).AddWhere("id = 1"), ExplainOptions{Analyze: true}).BuildQuery()
+		_, builderErr := Explain(NewReadQuery("orders"), ExplainOptions{}).BuildQuery()
+
+		Convey("It should returns error", func() {
+			So(mysqlErr, ShouldNotBeNil)
+			So(mysqlErr.Error(), ShouldEqual, "EXPLAIN VERBOSE and BUFFERS is not supported by mysql dialect")
+			So(sqliteErr, ShouldNotBeNil)
+			So(sqliteErr.Error(), ShouldEqual, "EXPLAIN option is not supported by sqlite dialect")
+			So(builderErr, ShouldNotBeNil)
+			So(builderErr.Error(), ShouldEqual, "no field selected, add it using AddSelect method")
+		})
+	})
+
+	Convey("Given postgres json plan", t, func() {
+		data, err := ioutil.ReadFile("testdata/plan.json")
+		So(err, ShouldBeNil)
+
+		plan, err := ParsePostgresPlan(data)
+
+		Convey("It should surface costs, row estimates and scans", func() {
+			So(err, ShouldBeNil)
+			So(plan.TotalCost(), ShouldEqual, 35.52)
+			So(plan.EstimatedRows(), ShouldEqual, 12)
+			So(plan.ExecutionTime, ShouldEqual, 0.152)
+			So(len(plan.Nodes()), ShouldEqual, 3)
+			So(len(plan.SeqScans()), ShouldEqual, 1)
+			So(plan.SeqScans()[0].RelationName, ShouldEqual, "customers")
+			So(plan.SeqScans()[0].Filter, ShouldEqual, "(region = 'eu'::text)")
+			So(plan.UsesIndex("orders_customer_id_idx"), ShouldBeTrue)
+			So(plan.UsesIndex(""), ShouldBeTrue)
+			So(plan.UsesIndex("orders_pkey"), ShouldBeFalse)
+			So(plan.IndexScans()[0].ActualLoops, ShouldEqual, 3)
+		})
+	})
+
+	Convey("Given invalid postgres plan", t, func() {
+		_, invalidErr := ParsePostgresPlan([]byte("Seq Scan on orders"))
+		_, emptyErr := ParsePostgresPlan([]byte("[]"))
+
+		Convey("It should returns error", func() {
+			So(invalidErr, ShouldNotBeNil)
+			So(emptyErr, ShouldNotBeNil)
+			So(emptyErr.Error(), ShouldEqual, "invalid postgres plan: expected 1 plan, got 0")
+		})
+	})
+}
@@ -0,0 +1,484 @@
+package squbix
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+const placeholder = '?'
+
+// Expr is query fragment with arguments bound to its ? placeholders. Placeholders inside string
+// literals, quoted identifiers and comments are ignored, use ?? to write literal question mark
+// outside of them, e.g. for postgres jsonb operators.
+type Expr struct {
+	SQL  string
+	Args []interface{}
+
+	// err fails building query using the expression, features are vendor specific features the
+	// expression is written with, building it in dialect not allowing them fails.
+	err      error
+	features []dialectFeature
+}
+
+// dialectFeature is vendor specific feature written for the dialects.
+type dialectFeature struct {
+	name     string
+	dialects []Dialect
+}
+
+// NewExpr creates query fragment binding args to its placeholders in order.
+func NewExpr(sql string, args ...interface{}) Expr {
+	return Expr{
+		SQL:  sql,
+		Args: args,
+	}
+}
+
+// As returns expression aliased with alias, e.g. for select list.
+func (ths Expr) As(alias string) Expr {
+	return formatExpr("%s AS %s", ths, rawExpr(alias))
+}
+
+// rawExpr creates expression from raw fragment, question marks in it are kept as is. Fragment
+// which can be read only with backslash escapes, e.g. mysql 'it\'s', is read as mysql.
+func rawExpr(sql string) Expr {
+	escaped := &strings.Builder{}
+
+	dialect := Standard
+	if _, err := lex(sql, false); err != nil {
+		if _, err := lex(sql, true); err == nil {
+			dialect = MySQL
+		}
+	}
+
+	scanPlaceholdersFor(sql, dialect, func(kind placeholderSegment, text string) {
+		switch kind {
+		case segmentText:
+			escaped.WriteString(text)
+		case segmentPlaceholder:
+			escaped.WriteString("??")
+		case segmentEscaped:
+			escaped.WriteString("????")
+		}
+	})
+
+	return Expr{SQL: escaped.String()}
+}
+
+func rawExprs(fragments []string) []Expr {
+	exprs := make([]Expr, 0, len(fragments))
+	for _, fragment := range fragments {
+		exprs = append(exprs, rawExpr(fragment))
+	}
+
+	return exprs
+}
+
+// formatExpr substitutes %s verbs of format with expressions, collecting their args in order.
+func formatExpr(format string, exprs ...Expr) Expr {
+	fragments := make([]interface{}, 0, len(exprs))
+	for _, expr := range exprs {
+		fragments = append(fragments, terminateLineComment(expr.SQL))
+	}
+
+	combined := combineExprs(exprs)
+	combined.SQL = fmt.Sprintf(format, fragments...)
+
+	return combined
+}
+
+// joinExprs joins expressions with separator, collecting their args in order.
+func joinExprs(exprs []Expr, separator string) Expr {
+	fragments := make([]string, 0, len(exprs))
+	for _, expr := range exprs {
+		fragments = append(fragments, terminateLineComment(expr.SQL))
+	}
+
+	combined := combineExprs(exprs)
+	combined.SQL = strings.Join(fragments, separator)
+
+	return combined
+}
+
+// combineExprs returns expression without sql collecting args, the first error and features of
+// the expressions.
+func combineExprs(exprs []Expr) Expr {
+	combined := Expr{Args: []interface{}{}}
+
+	for _, expr := range exprs {
+		combined.Args = append(combined.Args, expr.Args...)
+		combined.features = append(combined.features, expr.features...)
+
+		if combined.err == nil {
+			combined.err = expr.err
+		}
+	}
+
+	return combined
+}
+
+// requiring returns expression using feature supported by the dialects only.
+func (ths Expr) requiring(feature string, dialects ...Dialect) Expr {
+	ths.features = append(append([]dialectFeature{}, ths.features...), dialectFeature{name: feature, dialects: dialects})
+
+	return ths
+}
+
+// terminateLineComment ends fragment with new line when it ends inside line comment, so the comment
+// doesn't swallow query following the fragment.
+func terminateLineComment(sql string) string {
+	if !strings.Contains(sql, "--") {
+		return sql
+	}
+
+	tokens, err := tokenize(sql)
+	if err != nil || len(tokens) == 0 {
+		return sql
+	}
+
+	last := tokens[len(tokens)-1]
+	if last.kind == tokenComment && strings.HasPrefix(last.text, "--") && last.end == len(sql) {
+		return sql + "\n"
+	}
+
+	return sql
+}
+
+// exprSQLs returns sql of the expressions.
+func exprSQLs(exprs []Expr) []string {
+	fragments := make([]string, 0, len(exprs))
+	for _, expr := range exprs {
+		fragments = append(fragments, expr.SQL)
+	}
+
+	return fragments
+}
+
+// countPlaceholders returns number of placeholders in sql.
+func countPlaceholders(sql string) int {
+	count := 0
+
+	scanPlaceholders(sql, func(kind placeholderSegment, text string) {
+		if kind == segmentPlaceholder {
+			count++
+		}
+	})
+
+	return count
+}
+
+// renderOptions controls how expression is rendered into final query.
+type renderOptions struct {
+	dialect      Dialect
+	arrayBinding bool
+	inline       bool
+	format       Format
+}
+
+var (
+	inListOpening = regexp.MustCompile(`(?i)([\w.$"` + "`" + `]+|[\w.]*\([^()]*\))\s+(NOT\s+)?IN\s*\(\s*$`)
+	inListClosing = regexp.MustCompile(`^\s*\)`)
+)
+
+// renderExpr replaces placeholders of expression and normalizes whitespace into final query.
+// Slice bound to placeholder written directly as IN (?) is expanded into placeholder per element,
+// or bound as single array with = ANY(?) when array binding is enabled. Empty IN list renders as
+// FALSE and empty NOT IN list renders as TRUE, their left operand must be column or function call.
+func renderExpr(expr Expr, options renderOptions) (string, []interface{}, error) {
+	if options.arrayBinding && !options.dialect.allows(Postgres) {
+		return "", nil, options.dialect.unsupported("array binding")
+	}
+	if expr.err != nil {
+		return "", nil, expr.err
+	}
+	for _, feature := range expr.features {
+		if !options.dialect.allows(feature.dialects...) {
+			return "", nil, options.dialect.unsupported(feature.name)
+		}
+	}
+
+	segments := []sqlSegment{}
+	scanPlaceholdersFor(expr.SQL, options.dialect, func(kind placeholderSegment, text string) {
+		segments = append(segments, sqlSegment{kind: kind, text: text})
+	})
+
+	if count := countSegments(segments, segmentPlaceholder); count != len(expr.Args) {
+		return "", nil, fmt.Errorf("query has %d placeholder(s) but %d argument(s) bound", count, len(expr.Args))
+	}
+
+	for _, arg := range expr.Args {
+		if invalid, ok := arg.(invalidArg); ok {
+			return "", nil, invalid.err
+		}
+	}
+
+	args := []interface{}{}
+	bind := func(value interface{}) string {
+		args = append(args, value)
+
+		if options.inline {
+			return inlineMarkerOf(len(args))
+		}
+
+		return options.dialect.placeholder(len(args))
+	}
+
+	argIndex := 0
+	for index := range segments {
+		segment := &segments[index]
+
+		if segment.kind == segmentEscaped {
+			segment.text = string(placeholder)
+
+			continue
+		}
+		if segment.kind != segmentPlaceholder {
+			continue
+		}
+
+		value := expr.Args[argIndex]
+		argIndex++
+
+		list, ok := inList(segments, index, value)
+		if !ok {
+			segment.text = bind(value)
+
+			continue
+		}
+
+		previous, next := &segments[index-1], &segments[index+1]
+		opening := inListOpening.FindStringSubmatchIndex(previous.text)
+		negated := opening[4] >= 0
+
+		switch {
+		case list.Len() == 0:
+			start, err := emptyInListStart(previous.text, options.dialect)
+			if err != nil {
+				return "", nil, err
+			}
+
+			previous.text = previous.text[:start]
+			next.text = inListClosing.ReplaceAllString(next.text, "")
+			segment.text = "FALSE"
+			if negated {
+				segment.text = "TRUE"
+			}
+		case options.arrayBinding:
+			previous.text = previous.text[:opening[3]] + " = ANY("
+			if negated {
+				previous.text = previous.text[:opening[3]] + " <> ALL("
+			}
+			segment.text = bind(value)
+		default:
+			placeholders := make([]string, 0, list.Len())
+			for element := 0; element < list.Len(); element++ {
+				placeholders = append(placeholders, bind(list.Index(element).Interface()))
+			}
+			segment.text = strings.Join(placeholders, ", ")
+		}
+	}
+
+	rendered := &strings.Builder{}
+	for _, segment := range segments {
+		rendered.WriteString(segment.text)
+	}
+
+	query := normalizeWhitespace(rendered.String(), options.dialect)
+
+	if options.format == Pretty {
+		query = PrettyPrint(query)
+	}
+
+	if options.inline {
+		query = inlineArgs(query, args, options.dialect)
+	}
+
+	return query, args, nil
+}
+
+// normalizeWhitespace collapses whitespace between tokens of query into single space, keeping
+// string literals intact and ending line comments with new line so they don't swallow the rest of
+// query. Query the tokenizer can't read is collapsed as plain text.
+func normalizeWhitespace(query string, dialect Dialect) string {
+	tokens, err := tokenizeFor(query, dialect)
+	if err != nil {
+		return whitespaceNormalizer.ReplaceAllString(query, " ")
+	}
+
+	normalized := &strings.Builder{}
+	end := 0
+
+	for index, current := range tokens {
+		if current.start > end {
+			if index > 0 && strings.HasPrefix(tokens[index-1].text, "--") {
+				normalized.WriteString("\n")
+			} else {
+				normalized.WriteString(" ")
+			}
+		}
+
+		normalized.WriteString(current.text)
+		end = current.end
+	}
+
+	if end < len(query) {
+		normalized.WriteString(" ")
+	}
+
+	return normalized.String()
+}
+
+// inList returns value as list when it is slice or array, other than bytes, bound to placeholder
+// written directly as IN (?).
+func inList(segments []sqlSegment, index int, value interface{}) (reflect.Value, bool) {
+	list := reflect.ValueOf(value)
+	if list.Kind() != reflect.Slice && list.Kind() != reflect.Array {
+		return reflect.Value{}, false
+	}
+	if list.Type().Elem().Kind() == reflect.Uint8 {
+		return reflect.Value{}, false
+	}
+	if index == 0 || index == len(segments)-1 {
+		return reflect.Value{}, false
+	}
+
+	previous, next := segments[index-1], segments[index+1]
+	if previous.kind != segmentText || next.kind != segmentText {
+		return reflect.Value{}, false
+	}
+	if !inListOpening.MatchString(previous.text) || !inListClosing.MatchString(next.text) {
+		return reflect.Value{}, false
+	}
+
+	return list, true
+}
+
+// emptyInListBoundaries are keywords that can precede left operand of IN list predicate.
+var emptyInListBoundaries = map[string]bool{"AND": true, "OR": true, "NOT": true, "WHERE": true, "ON": true, "WHEN": true, "THEN": true, "ELSE": true, "HAVING": true}
+
+// emptyInListStart returns offset of left operand of IN list predicate text ends with, so the whole
+// predicate can be replaced by its value for empty list. The operand must be column or function
+// call, other expressions have no clear start.
+func emptyInListStart(text string, dialect Dialect) (int, error) {
+	tokens, err := tokenizeFor(text, dialect)
+	if err != nil {
+		return 0, err
+	}
+
+	// text ends with IN ( or NOT IN (.
+	end := len(tokens) - 2
+	if end > 0 && tokens[end-1].is("NOT") {
+		end--
+	}
+
+	start := end - 1
+	if start >= 0 && tokens[start].is(")") {
+		for depth := 0; start >= 0; start-- {
+			if tokens[start].is(")") {
+				depth++
+			} else if tokens[start].is("(") {
+				depth--
+			}
+
+			if depth == 0 {
+				break
+			}
+		}
+
+		start--
+	}
+
+	if start >= 0 && isIdentifierToken(tokens[start]) {
+		for start >= 2 && tokens[start-1].is(".") && isIdentifierToken(tokens[start-2]) {
+			start -= 2
+		}
+
+		if start == 0 || isInListBoundary(tokens[start-1]) {
+			return tokens[start].start, nil
+		}
+	}
+
+	operand := end - 1
+	for operand > 0 && !isInListBoundary(tokens[operand-1]) {
+		operand--
+	}
+
+	if end <= 0 || operand < 0 {
+		return 0, errors.New("empty list bound to IN needs column or function call as left operand")
+	}
+
+	return 0, fmt.Errorf("empty list bound to IN needs column or function call as left operand, got %q", text[tokens[operand].start:tokens[end-1].end])
+}
+
+func isInListBoundary(current token) bool {
+	return current.is("(") || current.is(",") || (current.kind == tokenWord && emptyInListBoundaries[strings.ToUpper(current.text)])
+}
+
+type sqlSegment struct {
+	kind placeholderSegment
+	text string
+}
+
+func countSegments(segments []sqlSegment, kind placeholderSegment) int {
+	count := 0
+	for _, segment := range segments {
+		if segment.kind == kind {
+			count++
+		}
+	}
+
+	return count
+}
+
+type placeholderSegment int
+
+const (
+	segmentText placeholderSegment = iota
+	segmentPlaceholder
+	segmentEscaped
+)
+
+// scanPlaceholders splits sql into text, placeholder and escaped question mark segments.
+// Placeholders are question marks of operator tokens, so text inside quotes and comments is never
+// treated as placeholder. Unterminated quote or comment swallows the rest of sql.
+func scanPlaceholders(sql string, visit func(kind placeholderSegment, text string)) {
+	scanPlaceholdersFor(sql, Standard, visit)
+}
+
+// scanPlaceholdersFor splits sql of the dialect into segments, see scanPlaceholders.
+func scanPlaceholdersFor(sql string, dialect Dialect, visit func(kind placeholderSegment, text string)) {
+	tokens, _ := lex(sql, dialect == MySQL)
+	start := 0
+
+	for _, current := range tokens {
+		if current.kind != tokenOperator || !strings.ContainsRune(current.text, placeholder) {
+			continue
+		}
+
+		for position := current.start; position < current.end; position++ {
+			if sql[position] != placeholder {
+				continue
+			}
+
+			if position > start {
+				visit(segmentText, sql[start:position])
+			}
+
+			if position+1 < current.end && sql[position+1] == placeholder {
+				visit(segmentEscaped, "??")
+				position++
+			} else {
+				visit(segmentPlaceholder, "?")
+			}
+
+			start = position + 1
+		}
+	}
+
+	if start < len(sql) {
+		visit(segmentText, sql[start:])
+	}
+}
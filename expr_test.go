@@ -0,0 +1,228 @@
+package squbix
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestExpr(t *testing.T) {
+	Convey("Given where expressions with bound arguments", t, func() {
+		query, args, err := NewReadQuery("table_a").
+			AddSelect("field_a").
+			AddWhere("table_a.kind = 'a?'").
+			AddWhereExpr(
+				NewExpr("table_a.id = ?", 1),
+				NewExpr("table_a.name = ? AND table_a.note <> '?'", "name"),
+			).
+			BuildQueryWithArgs()
+
+		Convey("It should returns generated query and arguments in order", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT field_a FROM table_a WHERE table_a.kind = 'a?' AND table_a.id = ? AND table_a.name = ? AND table_a.note <> '?'")
+			So(args, ShouldResemble, []interface{}{1, "name"})
+		})
+	})
+
+	Convey("Given raw fragment with question mark operator", t, func() {
+		query, args, err := NewReadQuery("table_a").
+			AddSelect("field_a").
+			AddWhere("data ? 'key'", "data ?| array['a', 'b']").
+			AddWhereExpr(NewExpr("data ?? ?", "other")).
+			BuildQueryWithArgs()
+
+		Convey("It should keep raw question marks as is", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT field_a FROM table_a WHERE data ? 'key' AND data ?| array['a', 'b'] AND data ? ?")
+			So(args, ShouldResemble, []interface{}{"other"})
+		})
+	})
+
+	Convey("Given expressions with bound arguments on create, update and delete", t, func() {
+		createQuery, createArgs, createErr := NewCreateQuery("table_a").
+			AddField("field_a", "field_b").
+			AddValueExpr(NewExpr("(?, ?)", 1, "a"), NewExpr("(?, ?)", 2, "b")).
+			BuildQueryWithArgs()
+		updateQuery, updateArgs, updateErr := NewUpdateQuery("table_a").
+			AddSetFieldExpr(NewExpr("field_a = ?", "A")).
+			AddWhereExpr(NewExpr("id = ?", 1)).
+			BuildQueryWithArgs()
+		deleteQuery, deleteArgs, deleteErr := NewDeleteQuery("table_a").
+			AddWhereExpr(NewExpr("id = ?", 1)).
+			BuildQueryWithArgs()
+
+		Convey("It should returns generated queries and arguments", func() {
+			So(createErr, ShouldBeNil)
+			So(createQuery, ShouldEqual, "INSERT INTO table_a (field_a, field_b) VALUES (?, ?), (?, ?)")
+			So(createArgs, ShouldResemble, []interface{}{1, "a", 2, "b"})
+			So(updateErr, ShouldBeNil)
+			So(updateQuery, ShouldEqual, "UPDATE table_a SET field_a = ? WHERE id = ?")
+			So(updateArgs, ShouldResemble, []interface{}{"A", 1})
+			So(deleteErr, ShouldBeNil)
+			So(deleteQuery, ShouldEqual, "DELETE FROM table_a WHERE id = ?")
+			So(deleteArgs, ShouldResemble, []interface{}{1})
+		})
+	})
+
+	Convey("Given expression with less arguments than placeholders", t, func() {
+		query, args, err := NewReadQuery("table_a").
+			AddSelect("field_a").
+			AddWhereExpr(NewExpr("id = ? OR parent_id = ?", 1)).
+			BuildQueryWithArgs()
+
+		Convey("It should returns error", func() {
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "query has 2 placeholder(s) but 1 argument(s) bound")
+			So(query, ShouldEqual, "")
+			So(args, ShouldBeNil)
+		})
+	})
+
+	Convey("Given slices bound to IN list", t, func() {
+		query, args, err := NewReadQuery("table_a").
+			AddSelect("field_a").
+			AddWhereExpr(
+				NewExpr("table_a.id IN (?)", []int64{1, 2, 3}),
+				NewExpr("table_a.kind NOT IN ( ? )", []string{"a"}),
+				NewExpr("table_a.hash = ?", []byte("hash")),
+				NewExpr("table_a.tags && ?", []string{"x"}),
+			).
+			BuildQueryWithArgs()
+
+		Convey("It should expand only IN lists into placeholder per element", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT field_a FROM table_a WHERE table_a.id IN (?, ?, ?) AND table_a.kind NOT IN ( ? ) AND table_a.hash = ? AND table_a.tags && ?")
+			So(args, ShouldResemble, []interface{}{int64(1), int64(2), int64(3), "a", []byte("hash"), []string{"x"}})
+		})
+	})
+
+	Convey("Given empty slices bound to IN list", t, func() {
+		query, args, err := NewReadQuery("table_a").
+			AddSelect("field_a").
+			AddWhereExpr(
+				NewExpr("table_a.id IN (?)", []int64{}),
+				NewExpr("lower(table_a.kind) NOT IN (?)", []string(nil)),
+				NewExpr("table_a.parent_id = ?", 1),
+			).
+			BuildQueryWithArgs()
+
+		Convey("It should render IN as FALSE and NOT IN as TRUE", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT field_a FROM table_a WHERE FALSE AND TRUE AND table_a.parent_id = ?")
+			So(args, ShouldResemble, []interface{}{1})
+		})
+	})
+
+	Convey("Given empty slices bound to IN list of quoted column or expression", t, func() {
+		query, args, err := NewReadQuery("table_a t").
+			AddSelect("field_a").
+			AddWhereExpr(
+				NewExpr(`t."my col" IN (?)`, []int{}),
+				NewExpr(`(t.kind = 'a' OR "Kind" NOT IN (?))`, []string{}),
+			).
+			BuildQueryWithArgs()
+		_, expressionErr := NewReadQuery("table_a").
+			AddSelect("field_a").
+			AddWhereExpr(NewExpr("a + 1 IN (?)", []int{})).
+			BuildQuery()
+		_, castErr := NewReadQuery("table_a").
+			WithDialect(Postgres).
+			AddSelect("field_a").
+			AddWhereExpr(NewExpr("a::int NOT IN (?)", []int{})).
+			BuildQuery()
+
+		Convey("It should replace the whole predicate of column and reject expression operand", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT field_a FROM table_a t WHERE FALSE AND (t.kind = 'a' OR TRUE)")
+			So(args, ShouldResemble, []interface{}{})
+			So(expressionErr, ShouldBeError, `empty list bound to IN needs column or function call as left operand, got "a + 1"`)
+			So(castErr, ShouldBeError, `empty list bound to IN needs column or function call as left operand, got "a::int"`)
+		})
+	})
+
+	Convey("Given slices bound to IN list with postgres array binding", t, func() {
+		ids := []int64{1, 2}
+
+		query, args, err := NewDeleteQuery("table_a").
+			WithDialect(Postgres).
+			WithArrayBinding().
+			AddWhereExpr(
+				NewExpr("kind = ?", "a"),
+				NewExpr("id IN (?)", ids),
+				NewExpr("parent_id NOT IN (?)", ids),
+				NewExpr("owner_id IN (?)", []int64{}),
+			).
+			BuildQueryWithArgs()
+
+		Convey("It should bind each slice as single array argument", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "DELETE FROM table_a WHERE kind = $1 AND id = ANY($2) AND parent_id <> ALL($3) AND FALSE")
+			So(args, ShouldResemble, []interface{}{"a", ids, ids})
+		})
+	})
+
+	Convey("Given fragments with line comments, string literals and unicode whitespace", t, func() {
+		query, err := NewReadQuery("orders").
+			AddSelect("id -- primary key\n", "note").
+			AddWhere("note = 'two  spaces'", "status\u00a0=\u00a0'paid' -- settled").
+			AddOrderBy("id").
+			BuildQuery()
+
+		Convey("It should keep literals and end line comments with new line", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT id -- primary key\n, note FROM orders WHERE note = 'two  spaces' AND status = 'paid' -- settled\nORDER BY id")
+		})
+	})
+
+	Convey("Given question marks inside literals with backslash escaped quotes", t, func() {
+		postgres, postgresArgs, postgresErr := NewReadQuery("notes").
+			WithDialect(Postgres).
+			AddSelect("id").
+			AddWhereExpr(NewExpr(`note = E'\'?' AND body = $$a ? b$$ AND id = ?`, 1)).
+			BuildQueryWithArgs()
+		mysql, mysqlArgs, mysqlErr := NewReadQuery("notes").
+			WithDialect(MySQL).
+			AddSelect("id").
+			AddWhere(`title <> 'it\'s ?'`).
+			AddWhereExpr(NewExpr(`note = 'it\'s ?' AND id = ?`, 1)).
+			BuildQueryWithArgs()
+
+		Convey("It should bind arguments to placeholders outside of the literals only", func() {
+			So(postgresErr, ShouldBeNil)
+			So(postgres, ShouldEqual, `SELECT id FROM notes WHERE note = E'\'?' AND body = $$a ? b$$ AND id = $1`)
+			So(postgresArgs, ShouldResemble, []interface{}{1})
+			So(mysqlErr, ShouldBeNil)
+			So(mysql, ShouldEqual, `SELECT id FROM notes WHERE title <> 'it\'s ?' AND note = 'it\'s ?' AND id = ?`)
+			So(mysqlArgs, ShouldResemble, []interface{}{1})
+		})
+	})
+
+	Convey("Given expressions carrying error or written for other dialect", t, func() {
+		failing := NewExpr("id = ?", 1)
+		failing.err = errors.New("invalid expression")
+		ilike := NewExpr("title ILIKE ?", "go%").requiring("ILIKE", Postgres)
+
+		_, failingErr := NewReadQuery("notes").
+			AddSelect("id").
+			AddWhereExpr(NewExpr("published = ?", true), failing).
+			BuildQuery()
+		_, mysqlErr := NewReadQuery("notes").
+			WithDialect(MySQL).
+			AddSelect("id").
+			AddWhereExpr(ilike).
+			BuildQuery()
+		postgres, postgresErr := NewReadQuery("notes").
+			WithDialect(Postgres).
+			AddSelect("id").
+			AddWhereExpr(ilike).
+			BuildQuery()
+
+		Convey("It should fail building query with the error or unsupported dialect", func() {
+			So(failingErr, ShouldBeError, "invalid expression")
+			So(mysqlErr, ShouldBeError, "ILIKE is not supported by mysql dialect")
+			So(postgresErr, ShouldBeNil)
+			So(postgres, ShouldEqual, "SELECT id FROM notes WHERE title ILIKE $1")
+		})
+	})
+}
@@ -0,0 +1,103 @@
+package squbix
+
+import (
+	"fmt"
+	"reflect"
+)
+
+const filterTag = "sq"
+
+// filterConditions returns where conditions of filter struct fields tagged with sq, e.g.
+// `sq:"status = ?"`. Nil pointers, nil slices and zero values are skipped, other values are
+// bound to every placeholder of the condition. Embedded structs are walked recursively.
+func filterConditions(filter interface{}) ([]Expr, error) {
+	value := reflect.ValueOf(filter)
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil, nil
+		}
+
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("filter must be struct or pointer to struct, got %T", filter)
+	}
+
+	return structConditions(value)
+}
+
+func structConditions(value reflect.Value) ([]Expr, error) {
+	conditions := []Expr{}
+	valueType := value.Type()
+
+	for index := 0; index < valueType.NumField(); index++ {
+		field := valueType.Field(index)
+		fieldValue := value.Field(index)
+		condition, tagged := field.Tag.Lookup(filterTag)
+
+		if !tagged && field.Anonymous && field.Type.Kind() == reflect.Struct {
+			if len(field.PkgPath) > 0 {
+				return nil, fmt.Errorf("filter field %s is unexported", field.Name)
+			}
+
+			embedded, err := structConditions(fieldValue)
+			if err != nil {
+				return nil, err
+			}
+
+			conditions = append(conditions, embedded...)
+
+			continue
+		}
+
+		if !tagged || condition == "-" {
+			continue
+		}
+		if len(field.PkgPath) > 0 {
+			return nil, fmt.Errorf("filter field %s is unexported", field.Name)
+		}
+		if isZero(fieldValue) {
+			continue
+		}
+
+		if fieldValue.Kind() == reflect.Ptr {
+			fieldValue = fieldValue.Elem()
+		}
+
+		conditions = append(conditions, bindEach(condition, fieldValue.Interface()))
+	}
+
+	return conditions, nil
+}
+
+// isZero reports whether value is nil or zero value of its type.
+func isZero(value reflect.Value) bool {
+	if !value.IsValid() {
+		return true
+	}
+
+	return value.IsZero()
+}
+
+// isZeroValue reports whether value is nil or zero value of its type.
+func isZeroValue(value interface{}) bool {
+	return isZero(reflect.ValueOf(value))
+}
+
+// isNilValue reports whether value is nil or nil pointer.
+func isNilValue(value interface{}) bool {
+	reflected := reflect.ValueOf(value)
+
+	return !reflected.IsValid() || (reflected.Kind() == reflect.Ptr && reflected.IsNil())
+}
+
+// bindEach creates expression binding value to every placeholder of sql.
+func bindEach(sql string, value interface{}) Expr {
+	args := []interface{}{}
+	for index := countPlaceholders(sql); index > 0; index-- {
+		args = append(args, value)
+	}
+
+	return NewExpr(sql, args...)
+}
@@ -0,0 +1,133 @@
+package squbix
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type Pagination struct {
+	AfterID *int64 `sq:"id > ?"`
+}
+
+type orderFilter struct {
+	Pagination
+	Status   *string  `sq:"status = ?"`
+	Term     *string  `sq:"(code ILIKE ? OR note ILIKE ?)"`
+	MinTotal *float64 `sq:"total >= ?"`
+	Customer int      `sq:"customer_id = ?"`
+	Ignored  *string  `sq:"-"`
+	Untagged *string
+}
+
+func TestConditionalHelpers(t *testing.T) {
+	Convey("Given conditions applied with When", t, func() {
+		query, err := NewReadQuery("table_a").
+			AddSelect("field_a").
+			When(true, func(builder *queryBuilder) {
+				builder.AddOrderBy("field_a DESC")
+			}).
+			When(false, func(builder *queryBuilder) {
+				builder.AddLimit(10)
+			}).
+			BuildQuery()
+
+		Convey("It should apply only the true ones", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT field_a FROM table_a ORDER BY field_a DESC")
+		})
+	})
+
+	Convey("Given where clauses added conditionally", t, func() {
+		query, args, err := NewReadQuery("table_a").
+			AddSelect("field_a").
+			AddWhereIf(true, "field_a IS NOT NULL").
+			AddWhereIf(false, "field_b IS NOT NULL").
+			AddWhereIfNotZero("paid", "status = ?").
+			AddWhereIfNotZero("", "code = ?").
+			AddWhereIfNotZero(0, "customer_id = ?").
+			AddWhereIfNotZero(nil, "parent_id = ?").
+			BuildQueryWithArgs()
+
+		Convey("It should skip false conditions and zero values", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT field_a FROM table_a WHERE field_a IS NOT NULL AND status = ?")
+			So(args, ShouldResemble, []interface{}{"paid"})
+		})
+	})
+
+	Convey("Given filter struct", t, func() {
+		status := "paid"
+		term := "%abc%"
+		afterID := int64(100)
+		ignored := "ignored"
+
+		query, args, err := NewReadQuery("orders").
+			AddSelect("id").
+			AddWhereFilter(&orderFilter{
+				Pagination: Pagination{AfterID: &afterID},
+				Status:     &status,
+				Term:       &term,
+				Customer:   7,
+				Ignored:    &ignored,
+				Untagged:   &ignored,
+			}).
+			BuildQueryWithArgs()
+
+		Convey("It should add condition of each set field", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT id FROM orders WHERE id > ? AND status = ? AND (code ILIKE ? OR note ILIKE ?) AND customer_id = ?")
+			So(args, ShouldResemble, []interface{}{int64(100), "paid", "%abc%", "%abc%", 7})
+		})
+	})
+
+	Convey("Given filter struct on update and delete", t, func() {
+		status := "paid"
+
+		updateQuery, updateArgs, updateErr := NewUpdateQuery("orders").
+			AddSetField("archived = TRUE").
+			AddWhereFilter(orderFilter{Status: &status}).
+			BuildQueryWithArgs()
+		deleteQuery, deleteArgs, deleteErr := NewDeleteQuery("orders").
+			AddWhereFilter(orderFilter{Status: &status}).
+			AddWhereIfNotZero(int64(0), "id = ?").
+			BuildQueryWithArgs()
+
+		Convey("It should add condition of each set field", func() {
+			So(updateErr, ShouldBeNil)
+			So(updateQuery, ShouldEqual, "UPDATE orders SET archived = TRUE WHERE status = ?")
+			So(updateArgs, ShouldResemble, []interface{}{"paid"})
+			So(deleteErr, ShouldBeNil)
+			So(deleteQuery, ShouldEqual, "DELETE FROM orders WHERE status = ?")
+			So(deleteArgs, ShouldResemble, []interface{}{"paid"})
+		})
+	})
+
+	Convey("Given filter which is not struct", t, func() {
+		query, err := NewReadQuery("orders").
+			AddSelect("id").
+			AddWhereFilter("status = 'paid'").
+			BuildQuery()
+
+		Convey("It should returns error", func() {
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "filter must be struct or pointer to struct, got string")
+			So(query, ShouldEqual, "")
+		})
+	})
+
+	Convey("Given create query with conditional values", t, func() {
+		query, err := NewCreateQuery("table_a").
+			AddField("field_a").
+			AddValue("(1)").
+			When(true, func(builder *createQueryBuilder) {
+				builder.AddOnConflict("ON CONFLICT DO NOTHING")
+			}).
+			BuildQuery()
+
+		Convey("It should apply the condition", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "INSERT INTO table_a (field_a) VALUES (1) ON CONFLICT DO NOTHING")
+		})
+	})
+}
@@ -0,0 +1,108 @@
+//go:build go1.18
+// +build go1.18
+
+package squbix
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// checkFuzzedQuery verifies properties every generated query must hold: builder rejects whitespace
+// only fragment with EmptyFragmentError and query built from self contained fragments passes syntax
+// check. Empty string may also mean the fragment is not set, e.g. table or on conflict, so it is
+// only checked for syntax.
+func checkFuzzedQuery(t *testing.T, query string, err error, fragments ...string) {
+	for _, fragment := range fragments {
+		if len(fragment) > 0 && len(strings.TrimSpace(fragment)) == 0 {
+			emptyFragment := &EmptyFragmentError{}
+			if !errors.As(err, &emptyFragment) {
+				t.Fatalf("expected empty fragment error for fragments %q, got %v", fragments, err)
+			}
+
+			return
+		}
+	}
+
+	if err != nil {
+		return
+	}
+
+	for _, fragment := range fragments {
+		// dollar quotes may legitimately pair up across fragments.
+		if checkSyntax(fragment) != nil || strings.Contains(fragment, "$") {
+			return
+		}
+	}
+
+	if err := checkSyntax(query); err != nil {
+		t.Fatalf("query %q built from fragments %q is malformed: %s", query, fragments, err)
+	}
+}
+
+func FuzzReadQuery(f *testing.F) {
+	f.Add("id", "orders o", "o.status IN ('paid', 'refunded')", "o.total DESC")
+	f.Add("COUNT(*)", "orders", "total > 100 -- large", "1")
+	f.Add("", " ", " ", "\t")
+	f.Add("a,", "(SELECT 1", "'unterminated", "/* open")
+
+	f.Fuzz(func(t *testing.T, field string, table string, where string, orderBy string) {
+		query, err := NewReadQuery(table).
+			AddSelect(field).
+			AddWhere(where).
+			AddOrderBy(orderBy).
+			BuildQuery()
+
+		checkFuzzedQuery(t, query, err, field, table, where, orderBy)
+	})
+}
+
+func FuzzCreateQuery(f *testing.F) {
+	f.Add("orders", "id", "(1)", "ON CONFLICT (id) DO NOTHING")
+	f.Add("orders", "note -- text", "('a, b')", "ON CONFLICT DO NOTHING")
+	f.Add(" ", "", "\n", " ")
+	f.Add("orders", "id,", "(1", "ON CONFLICT (")
+
+	f.Fuzz(func(t *testing.T, table string, field string, value string, onConflict string) {
+		query, err := NewCreateQuery(table).
+			AddField(field).
+			AddValue(value).
+			AddOnConflict(onConflict).
+			BuildQuery()
+
+		checkFuzzedQuery(t, query, err, table, field, value, onConflict)
+	})
+}
+
+func FuzzUpdateQuery(f *testing.F) {
+	f.Add("orders", "status = 'paid'", "id = 1")
+	f.Add("orders", "total = total + 1 -- bump", "id IN (1, 2)")
+	f.Add("", " ", " ")
+	f.Add("orders", "a = (", "id = ')'")
+
+	f.Fuzz(func(t *testing.T, table string, set string, where string) {
+		query, err := NewUpdateQuery(table).
+			AddSetField(set).
+			AddWhere(where).
+			BuildQuery()
+
+		checkFuzzedQuery(t, query, err, table, set, where)
+	})
+}
+
+func FuzzDeleteQuery(f *testing.F) {
+	f.Add("orders", "id = 1")
+	f.Add("orders o", "o.id IN (SELECT id FROM archived) -- cleanup")
+	f.Add(" ", "")
+	f.Add("orders", "id = (1")
+
+	f.Fuzz(func(t *testing.T, table string, where string) {
+		query, err := NewDeleteQuery(table).
+			HardDelete().
+			AddWhere(where).
+			BuildQuery()
+
+		checkFuzzedQuery(t, query, err, table, where)
+	})
+}
@@ -0,0 +1,6 @@
+// Package integration runs queries generated by squbix builders against real databases, an
+// embedded SQLite always and a local Postgres when SQUBIX_POSTGRES_DSN is set.
+//
+// It is separate module so database drivers stay out of squbix dependencies, run it with go test
+// from this directory.
+package integration
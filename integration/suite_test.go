@@ -0,0 +1,519 @@
+package integration
+
+import (
+	"database/sql"
+	"os"
+	"testing"
+
+	_ "github.com/lib/pq"
+	"github.com/nicklaros/squbix"
+	. "github.com/smartystreets/goconvey/convey"
+	_ "modernc.org/sqlite"
+)
+
+// postgresDSNEnv names environment variable holding dsn of local postgres the suite also runs
+// against, e.g. postgres://postgres@localhost/squbix_test?sslmode=disable. The suite only creates
+// temporary tables, which shadow tables of the same name and vanish with the connection, so
+// existing data is never touched.
+const postgresDSNEnv = "SQUBIX_POSTGRES_DSN"
+
+const schema = `
+CREATE TEMPORARY TABLE customers (
+	id INTEGER PRIMARY KEY,
+	name TEXT NOT NULL,
+	region TEXT NOT NULL
+);
+CREATE TEMPORARY TABLE orders (
+	id INTEGER PRIMARY KEY,
+	customer_id INTEGER NOT NULL REFERENCES customers (id),
+	status TEXT NOT NULL,
+	total INTEGER NOT NULL,
+	deleted_at TIMESTAMP NULL
+);
+`
+
+// target is database the suite runs against.
+type target struct {
+	name    string
+	dialect squbix.Dialect
+	open    func() (*sql.DB, error)
+}
+
+func targets() []target {
+	found := []target{
+		{
+			name:    "sqlite",
+			dialect: squbix.SQLite,
+			open: func() (*sql.DB, error) {
+				return sql.Open("sqlite", ":memory:")
+			},
+		},
+	}
+
+	if dsn := os.Getenv(postgresDSNEnv); len(dsn) > 0 {
+		found = append(found, target{
+			name:    "postgres",
+			dialect: squbix.Postgres,
+			open: func() (*sql.DB, error) {
+				return sql.Open("postgres", dsn)
+			},
+		})
+	}
+
+	return found
+}
+
+// setUp opens database of target with fresh schema and fixtures.
+func setUp(t *testing.T, target target) *sql.DB {
+	db, err := target.open()
+	if err != nil {
+		t.Fatalf("opening %s: %s", target.name, err)
+	}
+
+	// temporary tables exist only in connection that created them, keep single one.
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatalf("creating %s schema: %s", target.name, err)
+	}
+
+	exec(t, db, squbix.NewCreateQuery("customers").
+		WithDialect(target.dialect).
+		AddField("id", "name", "region").
+		AddValueExpr(
+			squbix.NewExpr("(?, ?, ?)", 1, "Ana", "eu"),
+			squbix.NewExpr("(?, ?, ?)", 2, "Budi", "asia"),
+			squbix.NewExpr("(?, ?, ?)", 3, "Cara", "eu"),
+		))
+
+	exec(t, db, squbix.NewCreateQuery("orders").
+		WithDialect(target.dialect).
+		AddField("id", "customer_id", "status", "total").
+		AddValueExpr(
+			squbix.NewExpr("(?, ?, ?, ?)", 1, 1, "paid", 100),
+			squbix.NewExpr("(?, ?, ?, ?)", 2, 1, "paid", 250),
+			squbix.NewExpr("(?, ?, ?, ?)", 3, 2, "pending", 75),
+			squbix.NewExpr("(?, ?, ?, ?)", 4, 3, "paid", 40),
+			squbix.NewExpr("(?, ?, ?, ?)", 5, 3, "refunded", 60),
+		))
+
+	return db
+}
+
+// exec executes query of builder and returns number of affected rows.
+func exec(t *testing.T, db *sql.DB, builder squbix.Builder) int64 {
+	query, args, err := builder.BuildQueryWithArgs()
+	if err != nil {
+		t.Fatalf("building query: %s", err)
+	}
+
+	result, err := db.Exec(query, args...)
+	if err != nil {
+		t.Fatalf("executing %s: %s", query, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		t.Fatalf("reading affected rows of %s: %s", query, err)
+	}
+
+	return affected
+}
+
+// queryInts executes query of builder and returns its single integer column.
+func queryInts(t *testing.T, db *sql.DB, builder squbix.Builder) []int64 {
+	query, args, err := builder.BuildQueryWithArgs()
+	if err != nil {
+		t.Fatalf("building query: %s", err)
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		t.Fatalf("executing %s: %s", query, err)
+	}
+	defer rows.Close()
+
+	values := []int64{}
+	for rows.Next() {
+		var value int64
+		if err := rows.Scan(&value); err != nil {
+			t.Fatalf("scanning %s: %s", query, err)
+		}
+
+		values = append(values, value)
+	}
+
+	if err := rows.Err(); err != nil {
+		t.Fatalf("reading %s: %s", query, err)
+	}
+
+	return values
+}
+
+func TestReadQuery(t *testing.T) {
+	for _, target := range targets() {
+		Convey("Given "+target.name+" database", t, func() {
+			db := setUp(t, target)
+			defer db.Close()
+
+			Convey("It should filter, join, order and paginate rows", func() {
+				ids := queryInts(t, db, squbix.NewReadQuery("orders o").
+					WithDialect(target.dialect).
+					AddSelect("o.id").
+					AddJoin("JOIN customers c ON c.id = o.customer_id").
+					AddWhereExpr(
+						squbix.NewExpr("c.region = ?", "eu"),
+						squbix.NewExpr("o.status IN (?)", []string{"paid", "refunded"}),
+					).
+					AddOrderBy("o.total DESC").
+					AddLimit(3).
+					AddOffset(1))
+
+				So(ids, ShouldResemble, []int64{1, 5, 4})
+			})
+
+			Convey("It should render empty IN and NOT IN lists", func() {
+				none := queryInts(t, db, squbix.NewReadQuery("orders").
+					WithDialect(target.dialect).
+					AddSelect("id").
+					AddWhereExpr(squbix.NewExpr("id IN (?)", []int64{})))
+				all := queryInts(t, db, squbix.NewReadQuery("orders").
+					WithDialect(target.dialect).
+					AddSelect("id").
+					AddWhereExpr(squbix.NewExpr("id NOT IN (?)", []int64{})).
+					AddOrderBy("id"))
+
+				So(none, ShouldBeEmpty)
+				So(all, ShouldResemble, []int64{1, 2, 3, 4, 5})
+			})
+
+			Convey("It should group, filter groups and number rows in windows", func() {
+				customers := queryInts(t, db, squbix.NewReadQuery("orders").
+					WithDialect(target.dialect).
+					AddSelect("customer_id").
+					AddGroupBy("customer_id").
+					AddHavingExpr(squbix.NewExpr("SUM(total) > ?", 100)).
+					AddOrderBy("customer_id"))
+				ranks := queryInts(t, db, squbix.NewReadQuery("orders").
+					WithDialect(target.dialect).
+					AddSelectExpr(squbix.OverWindow("ROW_NUMBER()", "by_customer")).
+					AddWindow("by_customer", squbix.NewWindow().PartitionBy("customer_id").OrderBy("total DESC")).
+					AddOrderBy("id"))
+
+				So(customers, ShouldResemble, []int64{1})
+				So(ranks, ShouldResemble, []int64{2, 1, 1, 2, 1})
+			})
+
+			Convey("It should count and check existence of filtered rows", func() {
+				paid := squbix.NewReadQuery("orders").
+					WithDialect(target.dialect).
+					AddSelect("id").
+					AddWhereExpr(squbix.NewExpr("status = ?", "paid")).
+					AddOrderBy("id").
+					AddLimit(1)
+
+				So(queryInts(t, db, paid.CountQuery()), ShouldResemble, []int64{3})
+				So(queryInts(t, db, paid.SumQuery("total")), ShouldResemble, []int64{390})
+				So(queryInts(t, db, squbix.NewReadQuery("orders").
+					WithDialect(target.dialect).
+					AddSelect("customer_id").
+					Distinct().
+					CountQuery()), ShouldResemble, []int64{3})
+
+				query, args, err := paid.ExistsQuery().BuildQueryWithArgs()
+				So(err, ShouldBeNil)
+
+				exists := false
+				So(db.QueryRow(query, args...).Scan(&exists), ShouldBeNil)
+				So(exists, ShouldBeTrue)
+			})
+		})
+	}
+}
+
+func TestCreateQuery(t *testing.T) {
+	for _, target := range targets() {
+		Convey("Given "+target.name+" database", t, func() {
+			db := setUp(t, target)
+			defer db.Close()
+
+			Convey("It should insert and upsert rows", func() {
+				inserted := exec(t, db, squbix.NewCreateQuery("customers").
+					WithDialect(target.dialect).
+					AddField("id", "name", "region").
+					AddValueExpr(squbix.NewExpr("(?, ?, ?)", 4, "Dewi", "asia")))
+				upserted := exec(t, db, squbix.NewCreateQuery("customers").
+					WithDialect(target.dialect).
+					AddField("id", "name", "region").
+					AddValueExpr(squbix.NewExpr("(?, ?, ?)", 4, "Dewi", "eu")).
+					AddOnConflict("ON CONFLICT (id) DO UPDATE SET region = EXCLUDED.region"))
+
+				So(inserted, ShouldEqual, 1)
+				So(upserted, ShouldEqual, 1)
+				So(queryInts(t, db, squbix.NewReadQuery("customers").
+					WithDialect(target.dialect).
+					AddSelect("id").
+					AddWhereExpr(squbix.NewExpr("region = ?", "eu")).
+					AddOrderBy("id")), ShouldResemble, []int64{1, 3, 4})
+			})
+
+			Convey("It should insert rows selected from other table", func() {
+				inserted := exec(t, db, squbix.NewCreateQuery("orders").
+					WithDialect(target.dialect).
+					AddField("id", "customer_id", "status", "total").
+					AddValueWithSelect("SELECT id + 10, id, 'pending', 0 FROM customers"))
+
+				So(inserted, ShouldEqual, 3)
+				So(queryInts(t, db, squbix.NewReadQuery("orders").
+					WithDialect(target.dialect).
+					AddSelect("id").
+					AddWhere("id > 10").
+					AddOrderBy("id")), ShouldResemble, []int64{11, 12, 13})
+			})
+		})
+	}
+}
+
+func TestUpdateQuery(t *testing.T) {
+	for _, target := range targets() {
+		Convey("Given "+target.name+" database", t, func() {
+			db := setUp(t, target)
+			defer db.Close()
+
+			Convey("It should update matching rows only", func() {
+				updated := exec(t, db, squbix.NewUpdateQuery("orders").
+					WithDialect(target.dialect).
+					AddSetFieldExpr(squbix.NewExpr("status = ?", "shipped"), squbix.NewExpr("total = total + ?", 5)).
+					AddWhereExpr(squbix.NewExpr("customer_id IN (?)", []int64{1, 2})))
+
+				So(updated, ShouldEqual, 3)
+				So(queryInts(t, db, squbix.NewReadQuery("orders").
+					WithDialect(target.dialect).
+					AddSelect("total").
+					AddWhereExpr(squbix.NewExpr("status = ?", "shipped")).
+					AddOrderBy("id")), ShouldResemble, []int64{105, 255, 80})
+			})
+		})
+	}
+}
+
+func TestDeleteQuery(t *testing.T) {
+	for _, target := range targets() {
+		Convey("Given "+target.name+" database", t, func() {
+			db := setUp(t, target)
+			defer db.Close()
+
+			Convey("It should delete matching rows only", func() {
+				deleted := exec(t, db, squbix.NewDeleteQuery("orders").
+					WithDialect(target.dialect).
+					AddWhereExpr(squbix.NewExpr("status = ?", "refunded")))
+
+				So(deleted, ShouldEqual, 1)
+				So(queryInts(t, db, squbix.NewReadQuery("orders").
+					WithDialect(target.dialect).
+					AddSelect("id").
+					AddOrderBy("id")), ShouldResemble, []int64{1, 2, 3, 4})
+			})
+
+			Convey("It should soft delete rows of registered table", func() {
+				squbix.RegisterSoftDelete("orders", squbix.SoftDelete{Column: "deleted_at", Value: "CURRENT_TIMESTAMP"})
+				defer squbix.UnregisterSoftDelete("orders")
+
+				deleted := exec(t, db, squbix.NewDeleteQuery("orders").
+					WithDialect(target.dialect).
+					AddWhereExpr(squbix.NewExpr("customer_id = ?", 3)))
+
+				So(deleted, ShouldEqual, 2)
+				So(queryInts(t, db, squbix.NewReadQuery("orders").
+					WithDialect(target.dialect).
+					AddSelect("id").
+					AddOrderBy("id")), ShouldResemble, []int64{1, 2, 3})
+				So(queryInts(t, db, squbix.NewReadQuery("orders").
+					WithDialect(target.dialect).
+					AddSelect("id").
+					OnlyDeleted().
+					AddOrderBy("id")), ShouldResemble, []int64{4, 5})
+			})
+		})
+	}
+}
+
+func TestRelations(t *testing.T) {
+	type order struct {
+		ID    int64 `json:"id"`
+		Total int64 `json:"total"`
+	}
+
+	for _, target := range targets() {
+		if target.dialect != squbix.Postgres {
+			continue
+		}
+
+		Convey("Given "+target.name+" database", t, func() {
+			db := setUp(t, target)
+			defer db.Close()
+
+			Convey("It should load customers with their orders in single query", func() {
+				query, args, err := squbix.NewReadQuery("customers").
+					WithDialect(target.dialect).
+					AddSelect("id").
+					WithMany("orders", squbix.NewReadQuery("orders").
+						AddSelect("id", "total").
+						AddWhereExpr(squbix.NewExpr("status = ?", "paid")).
+						AddOrderBy("id"), "customer_id = customers.id").
+					AddOrderBy("id").
+					BuildQueryWithArgs()
+				So(err, ShouldBeNil)
+
+				rows, err := db.Query(query, args...)
+				So(err, ShouldBeNil)
+				defer rows.Close()
+
+				loaded := map[int64][]order{}
+				for rows.Next() {
+					var id int64
+					orders := []order{}
+					So(rows.Scan(&id, squbix.ScanJSON(&orders)), ShouldBeNil)
+
+					loaded[id] = orders
+				}
+
+				So(rows.Err(), ShouldBeNil)
+				So(loaded, ShouldResemble, map[int64][]order{
+					1: {{ID: 1, Total: 100}, {ID: 2, Total: 250}},
+					2: {},
+					3: {{ID: 4, Total: 40}},
+				})
+			})
+		})
+	}
+}
+
+func TestTextSearch(t *testing.T) {
+	for _, target := range targets() {
+		Convey("Given "+target.name+" database", t, func() {
+			db := setUp(t, target)
+			defer db.Close()
+
+			table := "articles"
+			ddl := "CREATE TEMPORARY TABLE articles (id INTEGER PRIMARY KEY, title TEXT NOT NULL, body TEXT NOT NULL)"
+			search := squbix.NewTextSearch(target.dialect, "title", "body").Language("english")
+			if target.dialect == squbix.SQLite {
+				table = "articles_fts"
+				ddl = "CREATE VIRTUAL TABLE temp.articles_fts USING fts5(id UNINDEXED, title, body)"
+				search = squbix.NewTextSearch(target.dialect, table)
+			}
+
+			if _, err := db.Exec(ddl); err != nil {
+				t.Fatalf("creating %s articles: %s", target.name, err)
+			}
+
+			exec(t, db, squbix.NewCreateQuery(table).
+				WithDialect(target.dialect).
+				AddField("id", "title", "body").
+				AddValueExpr(
+					squbix.NewExpr("(?, ?, ?)", 1, "Cooking rice", "Rinse the rice before cooking"),
+					squbix.NewExpr("(?, ?, ?)", 2, "Rice fields", "Terraces of rice fields and rice farmers"),
+					squbix.NewExpr("(?, ?, ?)", 3, "Bread", "Knead the dough"),
+				))
+
+			Convey("It should find matching rows ordered by relevance", func() {
+				query, args, err := squbix.NewReadQuery(table).
+					WithDialect(target.dialect).
+					AddSelect("id").
+					AddSelectExpr(search.Rank("rice").As("relevance")).
+					AddWhereExpr(search.Match("rice")).
+					AddOrderBy("relevance DESC").
+					BuildQueryWithArgs()
+				So(err, ShouldBeNil)
+
+				rows, err := db.Query(query, args...)
+				So(err, ShouldBeNil)
+				defer rows.Close()
+
+				ids := []int64{}
+				for rows.Next() {
+					var id int64
+					var relevance float64
+					So(rows.Scan(&id, &relevance), ShouldBeNil)
+
+					ids = append(ids, id)
+				}
+
+				So(rows.Err(), ShouldBeNil)
+				So(ids, ShouldResemble, []int64{2, 1})
+			})
+		})
+	}
+}
+
+func TestTextMatch(t *testing.T) {
+	for _, target := range targets() {
+		Convey("Given "+target.name+" database", t, func() {
+			db := setUp(t, target)
+			defer db.Close()
+
+			exec(t, db, squbix.NewCreateQuery("customers").
+				WithDialect(target.dialect).
+				AddField("id", "name", "region").
+				AddValueExpr(squbix.NewExpr("(?, ?, ?)", 4, "50%_off", "eu")))
+
+			matching := func(condition squbix.Expr) []int64 {
+				return queryInts(t, db, squbix.NewReadQuery("customers").
+					WithDialect(target.dialect).
+					AddSelect("id").
+					AddWhereExpr(condition).
+					AddOrderBy("id"))
+			}
+
+			Convey("It should match wildcards of terms literally", func() {
+				So(matching(squbix.NewTextMatch(target.dialect, "name").Contains("%")), ShouldResemble, []int64{4})
+				So(matching(squbix.NewTextMatch(target.dialect, "name").EndsWith("_off")), ShouldResemble, []int64{4})
+				So(matching(squbix.NewTextMatch(target.dialect, "name").IgnoreCase().Contains("_")), ShouldResemble, []int64{4})
+			})
+
+			Convey("It should respect case unless ignored", func() {
+				So(matching(squbix.NewTextMatch(target.dialect, "name").StartsWith("a")), ShouldBeEmpty)
+				So(matching(squbix.NewTextMatch(target.dialect, "name").StartsWith("A")), ShouldResemble, []int64{1})
+				So(matching(squbix.NewTextMatch(target.dialect, "name").IgnoreCase().StartsWith("a")), ShouldResemble, []int64{1})
+				So(matching(squbix.NewTextMatch(target.dialect, "name").IgnoreCase().EndsWith("RA")), ShouldResemble, []int64{3})
+			})
+		})
+	}
+}
+
+func TestCaseExpr(t *testing.T) {
+	for _, target := range targets() {
+		Convey("Given "+target.name+" database", t, func() {
+			db := setUp(t, target)
+			defer db.Close()
+
+			Convey("It should order rows by case expression", func() {
+				So(queryInts(t, db, squbix.NewReadQuery("orders").
+					WithDialect(target.dialect).
+					AddSelect("id").
+					AddOrderByExpr(squbix.NewSimpleCase("status").When("pending", 0).Else(1).Expr()).
+					AddOrderBy("id")), ShouldResemble, []int64{3, 1, 2, 4, 5})
+			})
+
+			Convey("It should update many rows with different values in one statement", func() {
+				updated := exec(t, db, squbix.NewUpdateQuery("orders").
+					WithDialect(target.dialect).
+					AddBulkSet(squbix.NewBulkUpdate("id", "status", "total").
+						Row(1, "shipped", 110).
+						Row(3, "paid", 80)))
+
+				So(updated, ShouldEqual, 2)
+				So(queryInts(t, db, squbix.NewReadQuery("orders").
+					WithDialect(target.dialect).
+					AddSelect("total").
+					AddOrderBy("id")), ShouldResemble, []int64{110, 250, 80, 40, 60})
+				So(queryInts(t, db, squbix.NewReadQuery("orders").
+					WithDialect(target.dialect).
+					AddSelect("id").
+					AddWhereExpr(squbix.NewExpr("status = ?", "paid")).
+					AddOrderBy("id")), ShouldResemble, []int64{2, 3, 4})
+			})
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package squbix
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// JSON helpers generate postgres json and jsonb expressions, building them in other dialect fails.
+// Object keys and paths are written as escaped literals so expression indexes keep matching,
+// values are bound as arguments.
+
+// JSONField returns json value at path of column, e.g. data->'address'->'city'. Path elements are
+// object keys, or array indexes when they are integers.
+func JSONField(column string, path ...interface{}) Expr {
+	return postgresJSON(rawExpr(column + jsonPath(path, "->")))
+}
+
+// JSONText returns value at path of column as text, e.g. data->'address'->>'city'.
+func JSONText(column string, path ...interface{}) Expr {
+	if len(path) == 0 {
+		return postgresJSON(rawExpr(column + "::text"))
+	}
+
+	return postgresJSON(rawExpr(column + jsonPath(path[:len(path)-1], "->") + jsonPath(path[len(path)-1:], "->>")))
+}
+
+// JSONContains matches rows whose jsonb column contains value marshaled into json, e.g.
+// JSONContains("data", map[string]interface{}{"status": "paid"}).
+func JSONContains(column string, value interface{}) Expr {
+	return postgresJSON(NewExpr(column+" @> ?::jsonb", jsonArg(value)))
+}
+
+// JSONHasKey matches rows whose jsonb column has top-level key.
+func JSONHasKey(column string, key string) Expr {
+	return postgresJSON(NewExpr(column+" ?? ?", key))
+}
+
+// JSONHasAnyKey matches rows whose jsonb column has any of the top-level keys.
+func JSONHasAnyKey(column string, keys ...string) Expr {
+	return postgresJSON(jsonHasKeys(column, "??|", keys))
+}
+
+// JSONHasAllKeys matches rows whose jsonb column has all of the top-level keys.
+func JSONHasAllKeys(column string, keys ...string) Expr {
+	return postgresJSON(jsonHasKeys(column, "??&", keys))
+}
+
+// SetJSON returns set clause replacing value at path of jsonb column with value marshaled into
+// json, e.g. data = jsonb_set(data, ARRAY['status']::text[], ?::jsonb).
+func SetJSON(column string, path []string, value interface{}) Expr {
+	elements := make([]string, 0, len(path))
+	for _, element := range path {
+		elements = append(elements, stringLiteral(element, Postgres))
+	}
+
+	return postgresJSON(NewExpr(
+		fmt.Sprintf("%s = jsonb_set(%s, ARRAY[%s]::text[], ?::jsonb)", column, column, strings.Join(elements, ", ")),
+		jsonArg(value),
+	))
+}
+
+// JSONObject returns json object built from map of keys to column fragments, keys are ordered
+// alphabetically.
+func JSONObject(fields map[string]string) Expr {
+	return postgresJSON(rawExpr(fmt.Sprintf("JSON_BUILD_OBJECT(%s)", jsonObjectFields(fields))))
+}
+
+// JSONArray returns json array built from column fragments.
+func JSONArray(values ...string) Expr {
+	return postgresJSON(rawExpr(fmt.Sprintf("JSON_BUILD_ARRAY(%s)", strings.Join(values, ", "))))
+}
+
+// JSONAgg aggregates rows of joined table into json array of objects built from map of keys to
+// column fragments, ordered by the order by fragments. Rows missing from left join are skipped,
+// so group without joined rows gets empty array instead of array of null.
+func JSONAgg(table string, fields map[string]string, orderBy ...string) Expr {
+	order := ""
+	if len(orderBy) > 0 {
+		order = " ORDER BY " + strings.Join(orderBy, ", ")
+	}
+
+	return postgresJSON(rawExpr(fmt.Sprintf(
+		"COALESCE(JSON_AGG(JSON_BUILD_OBJECT(%s)%s) FILTER (WHERE NOT (%s IS NULL)), '[]'::json)",
+		jsonObjectFields(fields),
+		order,
+		table,
+	)))
+}
+
+// postgresJSON returns expression using postgres json operators and functions.
+func postgresJSON(expr Expr) Expr {
+	return expr.requiring("json operators", Postgres)
+}
+
+func jsonHasKeys(column string, operator string, keys []string) Expr {
+	if len(keys) == 0 {
+		return NewExpr(fmt.Sprintf("%s %s ARRAY[]::text[]", column, operator))
+	}
+
+	args := make([]interface{}, 0, len(keys))
+	for _, key := range keys {
+		args = append(args, key)
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(keys)), ", ")
+
+	return NewExpr(fmt.Sprintf("%s %s ARRAY[%s]", column, operator, placeholders), args...)
+}
+
+// jsonPath returns path elements each preceded by operator.
+func jsonPath(path []interface{}, operator string) string {
+	rendered := &strings.Builder{}
+
+	for _, element := range path {
+		rendered.WriteString(operator)
+
+		switch element := element.(type) {
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+			rendered.WriteString(fmt.Sprint(element))
+		default:
+			rendered.WriteString(stringLiteral(fmt.Sprint(element), Postgres))
+		}
+	}
+
+	return rendered.String()
+}
+
+func jsonObjectFields(fields map[string]string) string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, stringLiteral(key, Postgres)+", "+fields[key])
+	}
+
+	return strings.Join(pairs, ", ")
+}
+
+// jsonArg returns value marshaled into json text to bind, value that can't be marshaled is bound
+// as its error so building the query fails with it.
+func jsonArg(value interface{}) interface{} {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return invalidArg{err: fmt.Errorf("can't marshal json argument: %v", err)}
+	}
+
+	return string(data)
+}
+
+// invalidArg is argument that can't be bound, building query binding it returns its error.
+type invalidArg struct {
+	err error
+}
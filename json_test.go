@@ -0,0 +1,126 @@
+package squbix
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestJSON(t *testing.T) {
+	Convey("Given json path, containment and existence conditions", t, func() {
+		query, args, err := NewReadQuery("orders").
+			WithDialect(Postgres).
+			AddSelectExpr(
+				JSONText("data", "customer", "name").As("customer_name"),
+				JSONField("data", "items", 0).As("first_item"),
+			).
+			AddWhereExpr(
+				JSONContains("data", map[string]interface{}{"status": "paid"}),
+				JSONHasKey("data", "coupon"),
+				JSONHasAnyKey("data", "gift", "note"),
+				JSONHasAllKeys("data"),
+				NewExpr("data->>'it''s' = ?", "x"),
+			).
+			BuildQueryWithArgs()
+
+		Convey("It should write paths as literals and bind values", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT data->'customer'->>'name' AS customer_name, data->'items'->0 AS first_item FROM orders "+
+				"WHERE data @> $1::jsonb AND data ? $2 AND data ?| ARRAY[$3, $4] AND data ?& ARRAY[]::text[] AND data->>'it''s' = $5")
+			So(args, ShouldResemble, []interface{}{`{"status":"paid"}`, "coupon", "gift", "note", "x"})
+		})
+	})
+
+	Convey("Given jsonb set clause", t, func() {
+		query, args, err := NewUpdateQuery("orders").
+			WithDialect(Postgres).
+			AddSetFieldExpr(SetJSON("data", []string{"shipping", "o'clock"}, []int{1, 2})).
+			AddWhereExpr(NewExpr("id = ?", 1)).
+			BuildQueryWithArgs()
+
+		Convey("It should replace value at path with bound json", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "UPDATE orders SET data = jsonb_set(data, ARRAY['shipping', 'o''clock']::text[], $1::jsonb) WHERE id = $2")
+			So(args, ShouldResemble, []interface{}{"[1,2]", 1})
+		})
+	})
+
+	Convey("Given value that can't be marshaled into json", t, func() {
+		_, updateErr := NewUpdateQuery("orders").
+			AddSetFieldExpr(SetJSON("data", []string{"callback"}, func() {})).
+			AddWhere("id = 1").
+			BuildQuery()
+		_, readErr := NewReadQuery("orders").
+			AddSelect("id").
+			AddWhereExpr(JSONContains("data", map[string]interface{}{"total": make(chan int)})).
+			BuildQuery()
+
+		Convey("It should return the marshal error when building query", func() {
+			So(updateErr, ShouldBeError, "can't marshal json argument: json: unsupported type: func()")
+			So(readErr, ShouldBeError, "can't marshal json argument: json: unsupported type: chan int")
+		})
+	})
+
+	Convey("Given json objects, arrays and aggregation of joined table", t, func() {
+		query, err := NewReadQuery("orders o").
+			AddSelect("o.id").
+			AddSelectExpr(
+				JSONObject(map[string]string{"status": "o.status", "total": "o.total"}).As("summary"),
+				JSONArray("o.created_at", "o.updated_at").As("timestamps"),
+				JSONAgg("i", map[string]string{"sku": "i.sku", "quantity": "i.quantity"}, "i.id").As("items"),
+			).
+			AddJoin("LEFT JOIN items i ON i.order_id = o.id").
+			AddGroupBy("o.id").
+			BuildQuery()
+
+		Convey("It should build them with keys ordered alphabetically", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT o.id, JSON_BUILD_OBJECT('status', o.status, 'total', o.total) AS summary, "+
+				"JSON_BUILD_ARRAY(o.created_at, o.updated_at) AS timestamps, "+
+				"COALESCE(JSON_AGG(JSON_BUILD_OBJECT('quantity', i.quantity, 'sku', i.sku) ORDER BY i.id) FILTER (WHERE NOT (i IS NULL)), '[]'::json) AS items "+
+				"FROM orders o LEFT JOIN items i ON i.order_id = o.id GROUP BY o.id")
+		})
+	})
+
+	Convey("Given json helpers built in mysql and sqlite dialects", t, func() {
+		conditions := []Expr{
+			JSONText("data", "status"),
+			JSONContains("data", map[string]interface{}{"status": "paid"}),
+			JSONHasKey("data", "coupon"),
+			JSONHasAnyKey("data", "gift"),
+			JSONHasAllKeys("data", "gift"),
+		}
+		selected := []Expr{
+			JSONField("data", "items"),
+			JSONObject(map[string]string{"status": "status"}),
+			JSONArray("created_at"),
+			JSONAgg("i", map[string]string{"sku": "i.sku"}),
+		}
+
+		Convey("It should returns error of unsupported dialect", func() {
+			for _, dialect := range []Dialect{MySQL, SQLite} {
+				unsupported := "json operators is not supported by " + dialect.String() + " dialect"
+
+				for _, condition := range conditions {
+					_, err := NewReadQuery("orders").WithDialect(dialect).AddSelect("id").AddWhereExpr(condition).BuildQuery()
+
+					So(err, ShouldBeError, unsupported)
+				}
+
+				for _, expr := range selected {
+					_, err := NewReadQuery("orders").WithDialect(dialect).AddSelectExpr(expr.As("value")).BuildQuery()
+
+					So(err, ShouldBeError, unsupported)
+				}
+
+				_, err := NewUpdateQuery("orders").
+					WithDialect(dialect).
+					AddSetFieldExpr(SetJSON("data", []string{"status"}, "paid")).
+					AddWhere("id = 1").
+					BuildQuery()
+
+				So(err, ShouldBeError, unsupported)
+			}
+		})
+	})
+}
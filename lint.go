@@ -0,0 +1,138 @@
+package squbix
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+var (
+	// conjunctiveClauses are clauses joining their fragments with AND.
+	conjunctiveClauses = map[string]bool{
+		"where":  true,
+		"having": true,
+	}
+	// keywordClauses are clauses whose fragments start with keyword by design.
+	keywordClauses = map[string]bool{
+		"cte":               true,
+		"join":              true,
+		"value with select": true,
+		"on conflict":       true,
+	}
+)
+
+// LintOptions configures lint pass over raw fragments BuildQuery runs when enabled using WithLint.
+type LintOptions struct {
+	// Strict makes BuildQuery return the first issue found as error instead of warning about it.
+	Strict bool
+	// Warn receives issues found when not strict, they are written to standard logger when it is
+	// nil.
+	Warn func(issue *LintIssue)
+}
+
+// LintIssue is problem lint pass found in fragment of query.
+type LintIssue struct {
+	// Clause is clause the fragment was added to, e.g. "where" or "order by".
+	Clause string
+	// Fragment is the fragment as it was added to the builder.
+	Fragment string
+	// Problem describes what is wrong with the fragment.
+	Problem string
+}
+
+// Error returns description of the issue.
+func (ths *LintIssue) Error() string {
+	return fmt.Sprintf("%s fragment %q %s", ths.Clause, ths.Fragment, ths.Problem)
+}
+
+// lint checks fragments of the clauses, issues are returned as error in strict mode and reported
+// as warnings otherwise. Top-level OR is reported only when it isn't parenthesized by the builder.
+// Nil options disable the pass.
+func lint(options *LintOptions, parenthesized bool, clauses ...clauseFragments) error {
+	if options == nil {
+		return nil
+	}
+
+	for _, clause := range clauses {
+		for _, fragment := range clause.fragments {
+			problem := lintFragment(clause, fragment.SQL, parenthesized)
+			if len(problem) == 0 {
+				continue
+			}
+
+			issue := &LintIssue{
+				Clause:   clause.clause,
+				Fragment: fragmentText(fragment),
+				Problem:  problem,
+			}
+
+			switch {
+			case options.Strict:
+				return issue
+			case options.Warn != nil:
+				options.Warn(issue)
+			default:
+				log.Printf("squbix: %s", issue)
+			}
+		}
+	}
+
+	return nil
+}
+
+// lintFragment returns problem of single fragment of the clause, or empty string.
+func lintFragment(clause clauseFragments, fragment string, parenthesized bool) string {
+	if err := checkSyntax(fragment); err != nil {
+		return "has " + err.Error()
+	}
+
+	tokens, _ := tokenize(fragment)
+	first, last := nextTokenIndex(tokens, -1, 1), nextTokenIndex(tokens, len(tokens), -1)
+	if first < 0 {
+		return ""
+	}
+
+	conjunctive := conjunctiveClauses[clause.clause]
+
+	if !keywordClauses[clause.clause] && startsClause(tokens, first) ||
+		conjunctive && (tokens[first].is("AND") || tokens[first].is("OR")) {
+		return fmt.Sprintf("starts with stray %s keyword", keywordOf(tokens, first))
+	}
+
+	if conjunctive && (tokens[last].is("AND") || tokens[last].is("OR")) {
+		return fmt.Sprintf("ends with dangling %s keyword", keywordOf(tokens, last))
+	}
+
+	if conjunctive && !parenthesized && len(clause.fragments) > 1 && hasTopLevelOr(fragment, Standard) {
+		return "has top-level OR changing meaning of other conditions, wrap it in parentheses"
+	}
+
+	return ""
+}
+
+// keywordOf returns uppercased keyword at index, including BY of GROUP BY and ORDER BY.
+func keywordOf(tokens []token, index int) string {
+	keyword := strings.ToUpper(tokens[index].text)
+
+	if next := nextToken(tokens, index, 1); next != nil && next.is("BY") {
+		keyword += " BY"
+	}
+
+	return keyword
+}
+
+// fragmentText returns sql of fragment with escaped question marks unescaped, as it was written.
+func fragmentText(fragment Expr) string {
+	text := &strings.Builder{}
+
+	scanPlaceholders(fragment.SQL, func(kind placeholderSegment, segment string) {
+		switch kind {
+		case segmentEscaped:
+			text.WriteString(string(placeholder))
+		default:
+			text.WriteString(segment)
+		}
+	})
+
+	return text.String()
+}
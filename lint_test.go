@@ -0,0 +1,108 @@
+package squbix
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestLint(t *testing.T) {
+	Convey("Given fragments with mistakes and lint in warning mode", t, func() {
+		issues := []string{}
+		warn := func(issue *LintIssue) {
+			issues = append(issues, issue.Error())
+		}
+
+		query, err := NewReadQuery("orders").
+			WithoutAutoParenthesis().
+			WithLint(LintOptions{Warn: warn}).
+			AddSelect("id", "name,").
+			AddWhere("WHERE status = 'paid'", "total > 100 AND", "region = 'eu' OR region = 'asia'", "note = 'a OR b'").
+			AddWhere("id IN (SELECT order_id FROM items WHERE sku = ?)").
+			AddOrderBy("(id").
+			BuildQuery()
+
+		Convey("It should report every issue and still generate the query", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldStartWith, "SELECT id, name, FROM orders")
+			So(issues, ShouldResemble, []string{
+				`select fragment "name," has trailing comma at offset 4`,
+				`where fragment "WHERE status = 'paid'" starts with stray WHERE keyword`,
+				`where fragment "total > 100 AND" ends with dangling AND keyword`,
+				`where fragment "region = 'eu' OR region = 'asia'" has top-level OR changing meaning of other conditions, wrap it in parentheses`,
+				`order by fragment "(id" has unbalanced opening parenthesis at offset 0`,
+			})
+		})
+	})
+
+	Convey("Given fragments with mistakes and lint in strict mode", t, func() {
+		_, readErr := NewReadQuery("orders").
+			WithLint(LintOptions{Strict: true}).
+			AddSelect("id").
+			AddGroupBy("GROUP BY region").
+			BuildQuery()
+		_, createErr := NewCreateQuery("orders").
+			WithLint(LintOptions{Strict: true}).
+			AddField("id").
+			AddValue("('unterminated)").
+			BuildQuery()
+		_, updateErr := NewUpdateQuery("orders").
+			WithLint(LintOptions{Strict: true}).
+			AddSetField("SET status = 'paid'").
+			AddWhere("id = 1").
+			BuildQuery()
+		_, deleteErr := NewDeleteQuery("orders").
+			WithLint(LintOptions{Strict: true}).
+			AddWhere("id = 1", "OR 1 = 1").
+			BuildQuery()
+
+		Convey("It should returns the first issue as error", func() {
+			So(readErr, ShouldNotBeNil)
+			So(readErr.Error(), ShouldEqual, `group by fragment "GROUP BY region" starts with stray GROUP BY keyword`)
+			So(createErr, ShouldNotBeNil)
+			So(createErr.Error(), ShouldEqual, `value fragment "('unterminated)" has unterminated string literal at offset 1`)
+			So(updateErr, ShouldNotBeNil)
+			So(updateErr.Error(), ShouldEqual, `set fragment "SET status = 'paid'" starts with stray SET keyword`)
+			So(deleteErr, ShouldNotBeNil)
+			So(deleteErr.(*LintIssue).Clause, ShouldEqual, "where")
+			So(deleteErr.(*LintIssue).Problem, ShouldEqual, "starts with stray OR keyword")
+		})
+	})
+
+	Convey("Given well formed fragments and lint in strict mode", t, func() {
+		query, err := NewCreateQuery("orders").
+			WithLint(LintOptions{Strict: true}).
+			AddCTE("paid AS (SELECT id FROM payments)").
+			AddField("id", "status").
+			AddValueWithSelect("SELECT id, 'paid' FROM paid").
+			AddOnConflict("ON CONFLICT (id) DO NOTHING").
+			BuildQuery()
+
+		Convey("It should generate the query", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "WITH paid AS (SELECT id FROM payments) INSERT INTO orders (id, status) SELECT id, 'paid' FROM paid ON CONFLICT (id) DO NOTHING")
+		})
+	})
+
+	Convey("Given where fragments with top-level OR joined with other conditions", t, func() {
+		options := LintOptions{Strict: true}
+
+		parenthesized, parenthesizedErr := NewReadQuery("orders").
+			WithLint(options).
+			AddSelect("id").
+			AddWhere("status = 'paid' OR status = 'refunded'", "total > 0").
+			BuildQuery()
+		_, rawErr := NewDeleteQuery("orders").
+			WithoutAutoParenthesis().
+			WithLint(options).
+			AddWhere("status = 'paid' OR status = 'refunded'", "total > 0").
+			BuildQuery()
+
+		Convey("It should report them only when auto parenthesis is disabled", func() {
+			So(parenthesizedErr, ShouldBeNil)
+			So(parenthesized, ShouldEqual, "SELECT id FROM orders WHERE (status = 'paid' OR status = 'refunded') AND total > 0")
+			So(rawErr, ShouldNotBeNil)
+			So(rawErr.Error(), ShouldEqual, `where fragment "status = 'paid' OR status = 'refunded'" has top-level OR changing meaning of other conditions, wrap it in parentheses`)
+		})
+	})
+}
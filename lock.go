@@ -0,0 +1,81 @@
+package squbix
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type lockStrength string
+
+const (
+	lockUpdate      lockStrength = "UPDATE"
+	lockNoKeyUpdate lockStrength = "NO KEY UPDATE"
+	lockShare       lockStrength = "SHARE"
+	lockKeyShare    lockStrength = "KEY SHARE"
+)
+
+type lockWait string
+
+const (
+	lockWaitDefault lockWait = ""
+	lockSkipLocked  lockWait = "SKIP LOCKED"
+	lockNoWait      lockWait = "NOWAIT"
+)
+
+// rowLock is single locking clause of select query.
+type rowLock struct {
+	strength lockStrength
+	of       []string
+	wait     lockWait
+}
+
+// render returns locking clause in the dialect. Mysql has no key level locks, so they are
+// rendered as the nearest stronger lock.
+func (ths rowLock) render(dialect Dialect) (string, error) {
+	if !dialect.allows(Postgres, MySQL) {
+		return "", dialect.unsupported("row locking")
+	}
+
+	strength := ths.strength
+	if dialect == MySQL {
+		switch strength {
+		case lockNoKeyUpdate:
+			strength = lockUpdate
+		case lockKeyShare:
+			strength = lockShare
+		}
+	}
+
+	fragments := []string{"FOR", string(strength)}
+
+	if len(ths.of) > 0 {
+		fragments = append(fragments, "OF", strings.Join(ths.of, ", "))
+	}
+
+	if ths.wait != lockWaitDefault {
+		fragments = append(fragments, string(ths.wait))
+	}
+
+	return strings.Join(fragments, " "), nil
+}
+
+// lastLock returns locking clause the Of, SkipLocked and NoWait options apply to.
+func lastLock(locks []rowLock, option string) (*rowLock, error) {
+	if len(locks) == 0 {
+		return nil, fmt.Errorf("%s requires locking clause, add it using ForUpdate, ForNoKeyUpdate, ForShare or ForKeyShare method", option)
+	}
+
+	return &locks[len(locks)-1], nil
+}
+
+// setWait sets wait policy of locking clause.
+func (ths *rowLock) setWait(wait lockWait) error {
+	if ths.wait != lockWaitDefault && ths.wait != wait {
+		return errors.New("skip locked and nowait can't be used together")
+	}
+
+	ths.wait = wait
+
+	return nil
+}
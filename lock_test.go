@@ -0,0 +1,88 @@
+package squbix
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestLock(t *testing.T) {
+	Convey("Given job queue query locking rows", t, func() {
+		query, args, err := NewReadQuery("jobs").
+			WithDialect(Postgres).
+			AddSelect("id", "payload").
+			AddWhereExpr(NewExpr("status = ?", "queued")).
+			AddOrderBy("id").
+			AddLimit(10).
+			AddOffset(5).
+			ForUpdate().
+			SkipLocked().
+			BuildQueryWithArgs()
+
+		Convey("It should render locking clause after limit and offset", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT id, payload FROM jobs WHERE status = $1 ORDER BY id LIMIT 10 OFFSET 5 FOR UPDATE SKIP LOCKED")
+			So(args, ShouldResemble, []interface{}{"queued"})
+		})
+	})
+
+	Convey("Given several locking clauses restricted to tables", t, func() {
+		query, err := NewReadQuery("jobs j").
+			AddSelect("j.id").
+			AddJoin("JOIN queues q ON q.id = j.queue_id").
+			ForNoKeyUpdate().
+			Of("j").
+			NoWait().
+			ForKeyShare().
+			Of("q").
+			BuildQuery()
+
+		Convey("It should render each locking clause", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT j.id FROM jobs j JOIN queues q ON q.id = j.queue_id FOR NO KEY UPDATE OF j NOWAIT FOR KEY SHARE OF q")
+		})
+	})
+
+	Convey("Given key level locks on mysql", t, func() {
+		query, err := NewReadQuery("jobs").
+			WithDialect(MySQL).
+			AddSelect("id").
+			ForNoKeyUpdate().
+			SkipLocked().
+			ForKeyShare().
+			Of("jobs").
+			BuildQuery()
+
+		Convey("It should render the nearest stronger locks", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT id FROM jobs FOR UPDATE SKIP LOCKED FOR SHARE OF jobs")
+		})
+	})
+
+	Convey("Given invalid locking clauses", t, func() {
+		_, sqliteErr := NewReadQuery("jobs").
+			WithDialect(SQLite).
+			AddSelect("id").
+			ForShare().
+			BuildQuery()
+		_, withoutLockErr := NewReadQuery("jobs").
+			AddSelect("id").
+			SkipLocked().
+			BuildQuery()
+		_, bothErr := NewReadQuery("jobs").
+			AddSelect("id").
+			ForUpdate().
+			SkipLocked().
+			NoWait().
+			BuildQuery()
+
+		Convey("It should returns error", func() {
+			So(sqliteErr, ShouldNotBeNil)
+			So(sqliteErr.Error(), ShouldEqual, "row locking is not supported by sqlite dialect")
+			So(withoutLockErr, ShouldNotBeNil)
+			So(withoutLockErr.Error(), ShouldEqual, "SkipLocked requires locking clause, add it using ForUpdate, ForNoKeyUpdate, ForShare or ForKeyShare method")
+			So(bothErr, ShouldNotBeNil)
+			So(bothErr.Error(), ShouldEqual, "skip locked and nowait can't be used together")
+		})
+	})
+}
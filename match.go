@@ -0,0 +1,132 @@
+package squbix
+
+import (
+	"fmt"
+	"strings"
+)
+
+const likeEscape = '!'
+
+var (
+	likeEscaper = strings.NewReplacer(
+		string(likeEscape), string(likeEscape)+string(likeEscape),
+		"%", string(likeEscape)+"%",
+		"_", string(likeEscape)+"_",
+	)
+	globEscaper = strings.NewReplacer(
+		"[", "[[]",
+		"*", "[*]",
+		"?", "[?]",
+	)
+)
+
+// TextMatch builds pattern matching conditions on column in the dialect, binding the pattern
+// with wildcards of the term escaped. LIKE is case-sensitive except on sqlite, where GLOB is used
+// instead, and on mysql, where it follows collation of the column. Conditions of standard dialect
+// are written for postgres, building them in query of other dialect fails.
+type TextMatch struct {
+	dialect    Dialect
+	column     string
+	ignoreCase bool
+}
+
+// NewTextMatch creates case-sensitive pattern matching conditions on column in the dialect.
+func NewTextMatch(dialect Dialect, column string) *TextMatch {
+	return &TextMatch{
+		dialect: dialect,
+		column:  column,
+	}
+}
+
+// IgnoreCase makes the conditions case-insensitive, using ILIKE on postgres and comparing lower
+// cased column and pattern on other dialects.
+func (ths *TextMatch) IgnoreCase() *TextMatch {
+	ths.ignoreCase = true
+
+	return ths
+}
+
+// Contains matches rows whose column contains the term.
+func (ths *TextMatch) Contains(term string) Expr {
+	return ths.like("%", term, "%")
+}
+
+// StartsWith matches rows whose column starts with the term.
+func (ths *TextMatch) StartsWith(term string) Expr {
+	return ths.like("", term, "%")
+}
+
+// EndsWith matches rows whose column ends with the term.
+func (ths *TextMatch) EndsWith(term string) Expr {
+	return ths.like("%", term, "")
+}
+
+// Matches matches rows whose column matches regular expression pattern. Sqlite has REGEXP operator
+// only when regexp function is registered, case is ignored by prefixing pattern with (?i) flag
+// understood by Go and PCRE based implementations.
+func (ths *TextMatch) Matches(pattern string) Expr {
+	return ths.checked(ths.matches(pattern))
+}
+
+func (ths *TextMatch) matches(pattern string) Expr {
+	switch ths.dialect {
+	case MySQL:
+		matchType := "c"
+		if ths.ignoreCase {
+			matchType = "i"
+		}
+
+		return NewExpr(fmt.Sprintf("REGEXP_LIKE(%s, ?, '%s')", ths.column, matchType), pattern)
+	case SQLite:
+		if ths.ignoreCase {
+			pattern = "(?i)" + pattern
+		}
+
+		return NewExpr(ths.column+" REGEXP ?", pattern)
+	}
+
+	operator := "~"
+	if ths.ignoreCase {
+		operator = "~*"
+	}
+
+	return NewExpr(fmt.Sprintf("%s %s ?", ths.column, operator), pattern)
+}
+
+// like returns condition matching the term surrounded by wildcards.
+func (ths *TextMatch) like(prefix string, term string, suffix string) Expr {
+	return ths.checked(ths.pattern(prefix, term, suffix))
+}
+
+func (ths *TextMatch) pattern(prefix string, term string, suffix string) Expr {
+	if ths.dialect == SQLite && !ths.ignoreCase {
+		return NewExpr(ths.column+" GLOB ?", globWildcard(prefix)+globEscaper.Replace(term)+globWildcard(suffix))
+	}
+
+	pattern := prefix + likeEscaper.Replace(term) + suffix
+	escape := fmt.Sprintf(" ESCAPE '%c'", likeEscape)
+
+	switch {
+	case !ths.ignoreCase:
+		return NewExpr(ths.column+" LIKE ?"+escape, pattern)
+	case ths.dialect.allows(Postgres):
+		return NewExpr(ths.column+" ILIKE ?"+escape, pattern)
+	}
+
+	return NewExpr(fmt.Sprintf("LOWER(%s) LIKE LOWER(?)%s", ths.column, escape), pattern)
+}
+
+// checked returns the condition requiring dialect of the text match.
+func (ths *TextMatch) checked(expr Expr) Expr {
+	dialect := ths.dialect
+	if dialect == Standard {
+		dialect = Postgres
+	}
+
+	return expr.requiring(fmt.Sprintf("pattern matching of %s dialect", dialect), dialect)
+}
+
+// globWildcard returns GLOB wildcard matching what LIKE wildcard matches.
+func globWildcard(wildcard string) string {
+	return strings.Replace(wildcard, "%", "*", -1)
+}
@@ -0,0 +1,103 @@
+package squbix
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTextMatch(t *testing.T) {
+	Convey("Given postgres pattern matching conditions with wildcards in terms", t, func() {
+		sensitive := NewTextMatch(Postgres, "name")
+		insensitive := NewTextMatch(Postgres, "code").IgnoreCase()
+
+		query, args, err := NewReadQuery("products").
+			WithDialect(Postgres).
+			AddSelect("id").
+			AddWhereExpr(
+				sensitive.Contains("50%_off!"),
+				insensitive.StartsWith("a_b"),
+				insensitive.EndsWith("'x"),
+				sensitive.Matches("^[A-Z]+$"),
+				insensitive.Matches("sale"),
+			).
+			BuildQueryWithArgs()
+
+		Convey("It should bind escaped patterns with ESCAPE clause", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT id FROM products WHERE name LIKE $1 ESCAPE '!' AND code ILIKE $2 ESCAPE '!' AND code ILIKE $3 ESCAPE '!' "+
+				"AND name ~ $4 AND code ~* $5")
+			So(args, ShouldResemble, []interface{}{"%50!%!_off!!%", "a!_b%", "%'x", "^[A-Z]+$", "sale"})
+		})
+	})
+
+	Convey("Given mysql pattern matching conditions", t, func() {
+		sensitive := NewTextMatch(MySQL, "name")
+		insensitive := NewTextMatch(MySQL, "name").IgnoreCase()
+
+		query, args, err := NewReadQuery("products").
+			WithDialect(MySQL).
+			AddSelect("id").
+			AddWhereExpr(
+				sensitive.StartsWith(`C:\`),
+				insensitive.Contains("tea"),
+				sensitive.Matches("^a"),
+				insensitive.Matches("^b"),
+			).
+			BuildQueryWithArgs()
+
+		Convey("It should lower case both sides and use REGEXP_LIKE match types", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT id FROM products WHERE name LIKE ? ESCAPE '!' AND LOWER(name) LIKE LOWER(?) ESCAPE '!' "+
+				"AND REGEXP_LIKE(name, ?, 'c') AND REGEXP_LIKE(name, ?, 'i')")
+			So(args, ShouldResemble, []interface{}{`C:\%`, "%tea%", "^a", "^b"})
+		})
+	})
+
+	Convey("Given sqlite pattern matching conditions", t, func() {
+		sensitive := NewTextMatch(SQLite, "name")
+		insensitive := NewTextMatch(SQLite, "name").IgnoreCase()
+
+		query, args, err := NewReadQuery("products").
+			WithDialect(SQLite).
+			AddSelect("id").
+			AddWhereExpr(
+				sensitive.Contains("a*b?[c]"),
+				sensitive.EndsWith("50%"),
+				insensitive.StartsWith("x_"),
+				insensitive.Matches("^y"),
+			).
+			BuildQueryWithArgs()
+
+		Convey("It should use GLOB for case-sensitive matching", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT id FROM products WHERE name GLOB ? AND name GLOB ? AND LOWER(name) LIKE LOWER(?) ESCAPE '!' AND name REGEXP ?")
+			So(args, ShouldResemble, []interface{}{"*a[*]b[?][[]c]*", "*50%", "x!_%", "(?i)^y"})
+		})
+	})
+
+	Convey("Given pattern matching conditions built in query of other dialect", t, func() {
+		_, sqliteErr := NewReadQuery("products").
+			WithDialect(MySQL).
+			AddSelect("id").
+			AddWhereExpr(NewTextMatch(SQLite, "name").Contains("a")).
+			BuildQuery()
+		_, standardErr := NewReadQuery("products").
+			WithDialect(MySQL).
+			AddSelect("id").
+			AddWhereExpr(NewTextMatch(Standard, "name").IgnoreCase().Matches("^a")).
+			BuildQuery()
+		postgres, postgresErr := NewReadQuery("products").
+			WithDialect(Postgres).
+			AddSelect("id").
+			AddWhereExpr(NewTextMatch(Standard, "name").IgnoreCase().StartsWith("a")).
+			BuildQuery()
+
+		Convey("It should returns error of unsupported dialect", func() {
+			So(sqliteErr, ShouldBeError, "pattern matching of sqlite dialect is not supported by mysql dialect")
+			So(standardErr, ShouldBeError, "pattern matching of postgres dialect is not supported by mysql dialect")
+			So(postgresErr, ShouldBeNil)
+			So(postgres, ShouldEqual, "SELECT id FROM products WHERE name ILIKE $1 ESCAPE '!'")
+		})
+	})
+}
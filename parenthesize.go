@@ -0,0 +1,48 @@
+package squbix
+
+// hasTopLevelOr reports whether fragment has OR outside of parentheses, string literals and
+// comments, including || and XOR of mysql. Fragment the dialect can't tokenize is reported as
+// having it, so it is still wrapped.
+func hasTopLevelOr(fragment string, dialect Dialect) bool {
+	tokens, err := tokenizeFor(fragment, dialect)
+	if err != nil {
+		return true
+	}
+
+	depth := 0
+
+	for _, current := range tokens {
+		switch {
+		case current.is("("):
+			depth++
+		case current.is(")"):
+			depth--
+		case depth == 0 && current.is("OR"):
+			return true
+		case depth == 0 && dialect == MySQL && (current.is("||") || current.is("XOR")):
+			return true
+		}
+	}
+
+	return false
+}
+
+// parenthesizeOr wraps fragments having top-level OR in parentheses when they are joined with
+// other fragments using AND.
+func parenthesizeOr(fragments []Expr, dialect Dialect) []Expr {
+	if len(fragments) < 2 {
+		return fragments
+	}
+
+	parenthesized := make([]Expr, 0, len(fragments))
+
+	for _, fragment := range fragments {
+		if hasTopLevelOr(fragment.SQL, dialect) {
+			fragment = formatExpr("(%s)", fragment)
+		}
+
+		parenthesized = append(parenthesized, fragment)
+	}
+
+	return parenthesized
+}
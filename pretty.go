@@ -0,0 +1,254 @@
+package squbix
+
+import (
+	"strings"
+)
+
+const prettyIndent = "  "
+
+// Format is layout of generated query.
+type Format int
+
+const (
+	// Compact generates query on single line, it is the default format of every builder.
+	Compact Format = iota
+	// Pretty generates query formatted by PrettyPrint.
+	Pretty
+)
+
+var (
+	clauseKeywords = map[string]bool{
+		"SELECT":    true,
+		"WHERE":     true,
+		"HAVING":    true,
+		"WINDOW":    true,
+		"LIMIT":     true,
+		"OFFSET":    true,
+		"RETURNING": true,
+		"UNION":     true,
+		"INTERSECT": true,
+		"EXCEPT":    true,
+		"SET":       true,
+	}
+	joinModifiers = map[string]bool{
+		"LEFT":    true,
+		"RIGHT":   true,
+		"FULL":    true,
+		"INNER":   true,
+		"CROSS":   true,
+		"NATURAL": true,
+		"OUTER":   true,
+	}
+	listKeywords = map[string]bool{
+		"SELECT": true,
+		"VALUES": true,
+		"SET":    true,
+	}
+	subqueryKeywords = map[string]bool{
+		"SELECT": true,
+		"WITH":   true,
+		"VALUES": true,
+		"INSERT": true,
+		"UPDATE": true,
+		"DELETE": true,
+	}
+)
+
+// PrettyPrint formats query putting each major clause on its own line, each item of select, values
+// and set lists with more than one item on its own indented line and indenting subqueries. Query
+// the tokenizer can't read is returned as is.
+func PrettyPrint(query string) string {
+	tokens, err := tokenize(query)
+	if err != nil || len(tokens) == 0 {
+		return query
+	}
+
+	printed := &strings.Builder{}
+	scopes := []prettyScope{}
+	indent := 0
+	lineIndent := 0
+	end := tokens[0].start
+	openedSubquery := false
+	listItems := map[int]bool{}
+
+	newLine := func(depth int) {
+		printed.WriteString("\n" + strings.Repeat(prettyIndent, depth))
+		lineIndent = depth
+	}
+
+	for index, current := range tokens {
+		inClauses := len(scopes) == 0 || scopes[len(scopes)-1].subquery
+
+		switch {
+		case current.is(")") && len(scopes) > 0:
+			scope := scopes[len(scopes)-1]
+			scopes = scopes[:len(scopes)-1]
+
+			if scope.subquery {
+				indent = scope.indent
+				newLine(scope.lineIndent)
+			} else {
+				printed.WriteString(query[end:current.start])
+			}
+		case listItems[index]:
+			newLine(indent + 1)
+		case openedSubquery || index > 0 && inClauses && startsClause(tokens, index):
+			newLine(indent)
+		default:
+			printed.WriteString(query[end:current.start])
+		}
+
+		if inClauses && current.kind == tokenWord && listKeywords[strings.ToUpper(current.text)] {
+			for _, start := range listItemStarts(tokens, index) {
+				listItems[start] = true
+			}
+		}
+
+		printed.WriteString(query[current.start:current.end])
+		end = current.end
+		openedSubquery = false
+
+		if current.is("(") {
+			next := nextToken(tokens, index, 1)
+			scope := prettyScope{
+				subquery:   next != nil && next.kind == tokenWord && subqueryKeywords[strings.ToUpper(next.text)],
+				indent:     indent,
+				lineIndent: lineIndent,
+			}
+
+			scopes = append(scopes, scope)
+			if scope.subquery {
+				indent = lineIndent + 1
+				openedSubquery = true
+			}
+		}
+	}
+
+	return printed.String()
+}
+
+// prettyScope is parenthesized part of query being printed, indent and lineIndent are the ones
+// before the parenthesis was opened.
+type prettyScope struct {
+	subquery   bool
+	indent     int
+	lineIndent int
+}
+
+// listItemStarts returns indexes of tokens starting items of list following the keyword at index,
+// or nil when the list has single item.
+func listItemStarts(tokens []token, index int) []int {
+	start := index + 1
+
+	if tokens[index].is("SELECT") {
+		for start < len(tokens) && (tokens[start].is("DISTINCT") || tokens[start].is("ALL")) {
+			start++
+		}
+
+		if start < len(tokens) && tokens[start].is("ON") && start+1 < len(tokens) && tokens[start+1].is("(") {
+			start = closingParenthesis(tokens, start+1) + 1
+		}
+	}
+
+	starts := []int{start}
+	depth := 0
+
+	for position := start; position < len(tokens); position++ {
+		current := tokens[position]
+
+		if depth == 0 && (current.is(")") || startsClause(tokens, position)) {
+			break
+		}
+
+		switch {
+		case current.is("("):
+			depth++
+		case current.is(")"):
+			depth--
+		case depth == 0 && current.is(",") && position+1 < len(tokens):
+			starts = append(starts, position+1)
+		}
+	}
+
+	if len(starts) < 2 {
+		return nil
+	}
+
+	return starts
+}
+
+// closingParenthesis returns index of parenthesis closing the one at index.
+func closingParenthesis(tokens []token, index int) int {
+	depth := 0
+
+	for position := index; position < len(tokens); position++ {
+		switch {
+		case tokens[position].is("("):
+			depth++
+		case tokens[position].is(")"):
+			depth--
+			if depth == 0 {
+				return position
+			}
+		}
+	}
+
+	return len(tokens) - 1
+}
+
+// startsClause reports whether token at index starts major clause of the query.
+func startsClause(tokens []token, index int) bool {
+	current := tokens[index]
+	if current.kind != tokenWord {
+		return false
+	}
+
+	word := strings.ToUpper(current.text)
+	previous := nextToken(tokens, index, -1)
+	next := nextToken(tokens, index, 1)
+
+	switch word {
+	case "FROM":
+		return previous == nil || !previous.is("DISTINCT") && !previous.is("DELETE")
+	case "GROUP":
+		return next != nil && next.is("BY") && (previous == nil || !previous.is("WITHIN"))
+	case "ORDER":
+		return next != nil && next.is("BY")
+	case "FOR":
+		return next != nil && (next.is("UPDATE") || next.is("SHARE") || next.is("NO") || next.is("KEY"))
+	case "ON":
+		return next != nil && next.is("CONFLICT")
+	case "VALUES":
+		return previous == nil || !previous.is("DEFAULT")
+	case "JOIN":
+		return previous == nil || !joinModifiers[strings.ToUpper(previous.text)]
+	}
+
+	if joinModifiers[word] && word != "OUTER" {
+		return next != nil && (next.is("JOIN") || joinModifiers[strings.ToUpper(next.text)]) &&
+			(previous == nil || !previous.is("NATURAL"))
+	}
+
+	return clauseKeywords[word]
+}
+
+// nextToken returns nearest token other than comment in the direction from index, or nil.
+func nextToken(tokens []token, index int, direction int) *token {
+	if index = nextTokenIndex(tokens, index, direction); index < 0 {
+		return nil
+	}
+
+	return &tokens[index]
+}
+
+// nextTokenIndex returns index of nearest token other than comment in the direction from index, or
+// -1.
+func nextTokenIndex(tokens []token, index int, direction int) int {
+	for index += direction; index >= 0 && index < len(tokens); index += direction {
+		if tokens[index].kind != tokenComment {
+			return index
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,120 @@
+package squbix
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPrettyPrint(t *testing.T) {
+	Convey("Given query with subqueries", t, func() {
+		query, err := NewReadQuery("orders o").
+			AddCTE("paid AS (SELECT id FROM payments WHERE status = 'paid')").
+			AddSelect("o.id", "EXTRACT(YEAR FROM o.created_at) AS year").
+			AddJoin("LEFT OUTER JOIN customers c ON c.id = o.customer_id", "JOIN paid p ON p.id = o.id").
+			AddWhere("o.a IS DISTINCT FROM o.b", "EXISTS (SELECT 1 FROM items i WHERE i.order_id = o.id)").
+			AddGroupBy("o.id").
+			AddOrderBy("o.id").
+			AddLimit(5).
+			ForUpdate().
+			BuildQuery()
+		So(err, ShouldBeNil)
+
+		Convey("It should put each clause on its own line and indent subqueries", func() {
+			So(PrettyPrint(query), ShouldEqual, `WITH paid AS (
+  SELECT id
+  FROM payments
+  WHERE status = 'paid'
+)
+SELECT
+  o.id,
+  EXTRACT(YEAR FROM o.created_at) AS year
+FROM orders o
+LEFT OUTER JOIN customers c ON c.id = o.customer_id
+JOIN paid p ON p.id = o.id
+WHERE o.a IS DISTINCT FROM o.b AND EXISTS (
+  SELECT 1
+  FROM items i
+  WHERE i.order_id = o.id
+)
+GROUP BY o.id
+ORDER BY o.id
+LIMIT 5
+FOR UPDATE`)
+		})
+	})
+
+	Convey("Given insert with conflict clause", t, func() {
+		query, err := NewCreateQuery("table_a").
+			AddField("field_a").
+			AddValue("(1)").
+			AddOnConflict("ON CONFLICT (field_a) DO UPDATE SET field_a = EXCLUDED.field_a").
+			BuildQuery()
+		So(err, ShouldBeNil)
+
+		Convey("It should put each clause on its own line", func() {
+			So(PrettyPrint(query), ShouldEqual, `INSERT INTO table_a (field_a)
+VALUES (1)
+ON CONFLICT (field_a) DO UPDATE
+SET field_a = EXCLUDED.field_a`)
+		})
+	})
+
+	Convey("Given query the tokenizer can't read", t, func() {
+		Convey("It should returns the query as is", func() {
+			So(PrettyPrint("SELECT 'unterminated FROM t"), ShouldEqual, "SELECT 'unterminated FROM t")
+		})
+	})
+
+	Convey("Given builders with pretty format", t, func() {
+		readQuery, readArgs, readErr := NewReadQuery("orders").
+			WithFormat(Pretty).
+			AddSelect("customer_id", "(SELECT name FROM customers c WHERE c.id = customer_id) AS name", "SUM(total) AS total").
+			Distinct().
+			AddWhereExpr(NewExpr("status = ?", "paid")).
+			AddGroupBy("customer_id").
+			BuildQueryWithArgs()
+		createQuery, createErr := NewCreateQuery("table_a").
+			WithFormat(Pretty).
+			AddField("field_a", "field_b").
+			AddValue("(1, 'a')", "(2, 'b')").
+			BuildQuery()
+		updateQuery, updateErr := NewUpdateQuery("table_a").
+			WithFormat(Pretty).
+			AddSetField("field_a = 1", "field_b = 'b'").
+			AddWhere("id = 1").
+			BuildQuery()
+		deleteDebug := NewDeleteQuery("table_a").
+			WithFormat(Pretty).
+			AddWhereExpr(NewExpr("id = ? AND note = ?", 1, "a\nb")).
+			DebugString()
+
+		Convey("It should returns formatted queries", func() {
+			So(readErr, ShouldBeNil)
+			So(readQuery, ShouldEqual, `SELECT DISTINCT
+  customer_id,
+  (
+    SELECT name
+    FROM customers c
+    WHERE c.id = customer_id
+  ) AS name,
+  SUM(total) AS total
+FROM orders
+WHERE status = ?
+GROUP BY customer_id`)
+			So(readArgs, ShouldResemble, []interface{}{"paid"})
+			So(createErr, ShouldBeNil)
+			So(createQuery, ShouldEqual, `INSERT INTO table_a (field_a, field_b)
+VALUES
+  (1, 'a'),
+  (2, 'b')`)
+			So(updateErr, ShouldBeNil)
+			So(updateQuery, ShouldEqual, `UPDATE table_a
+SET
+  field_a = 1,
+  field_b = 'b'
+WHERE id = 1`)
+			So(deleteDebug, ShouldEqual, "/* DEBUG ONLY, NOT FOR EXECUTION */ DELETE FROM table_a\nWHERE id = 1 AND note = 'a\nb'")
+		})
+	})
+}
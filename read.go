@@ -4,60 +4,105 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
-	"strings"
 )
 
 var whitespaceNormalizer = regexp.MustCompile(`\s+`)
 
 type queryBuilder struct {
-	cteFragments     []string
-	selectFragments  []string
-	fromFragments    []string
-	joinFragments    []string
-	whereFragments   []string
-	groupByFragments []string
-	orderByFragments []string
+	cteFragments     []Expr
+	selectFragments  []Expr
+	distinct         bool
+	distinctOn       []Expr
+	fromFragments    []Expr
+	joinFragments    []Expr
+	whereFragments   []Expr
+	guards           []Expr
+	groupByFragments []Expr
+	havingFragments  []Expr
+	windowFragments  []Expr
+	orderByFragments []Expr
 	limit            *int32
 	offset           *int32
+	locks            []rowLock
+	relations        []relation
+	softDeleteScope  softDeleteScope
+	schema           *Schema
+	lint             *LintOptions
+	rawConditions    bool
+	options          renderOptions
+	err              error
 }
 
+// ReadQueryBuilder is sql builder for select operation, it allows the builder to be referred from other packages.
+type ReadQueryBuilder = queryBuilder
+
 // NewReadQuery creates new sql builder instance for select operation.
 func NewReadQuery(table string) *queryBuilder {
 	return &queryBuilder{
-		fromFragments: []string{table},
+		fromFragments: []Expr{rawExpr(table)},
 	}
 }
 
 // AddCTE adds common table expression to include in generated query.
 func (ths *queryBuilder) AddCTE(CTEs ...string) *queryBuilder {
-	ths.cteFragments = append(ths.cteFragments, CTEs...)
+	ths.cteFragments = append(ths.cteFragments, rawExprs(CTEs)...)
 
 	return ths
 }
 
 // AddSelect adds field to select in generated query.
 func (ths *queryBuilder) AddSelect(fields ...string) *queryBuilder {
+	ths.selectFragments = append(ths.selectFragments, rawExprs(fields)...)
+
+	return ths
+}
+
+// AddSelectExpr adds field with bound arguments to select in generated query, e.g. window
+// function made by Over.
+func (ths *queryBuilder) AddSelectExpr(fields ...Expr) *queryBuilder {
 	ths.selectFragments = append(ths.selectFragments, fields...)
 
 	return ths
 }
 
+// Distinct removes duplicate rows from result of generated query.
+func (ths *queryBuilder) Distinct() *queryBuilder {
+	ths.distinct = true
+
+	return ths
+}
+
+// DistinctOn keeps only first row of each set of rows equal on the expressions, it must match
+// leftmost order by expressions and requires postgres dialect.
+func (ths *queryBuilder) DistinctOn(exprs ...string) *queryBuilder {
+	ths.distinctOn = append(ths.distinctOn, rawExprs(exprs)...)
+
+	return ths
+}
+
 // AddFrom adds table to select from in generated query.
 func (ths *queryBuilder) AddFrom(tables ...string) *queryBuilder {
-	ths.fromFragments = append(ths.fromFragments, tables...)
+	ths.fromFragments = append(ths.fromFragments, rawExprs(tables)...)
 
 	return ths
 }
 
 // AddJoin adds table to join.
 func (ths *queryBuilder) AddJoin(tables ...string) *queryBuilder {
-	ths.joinFragments = append(ths.joinFragments, tables...)
+	ths.joinFragments = append(ths.joinFragments, rawExprs(tables)...)
 
 	return ths
 }
 
 // AddWhere adds where clause in generated query.
 func (ths *queryBuilder) AddWhere(where ...string) *queryBuilder {
+	ths.whereFragments = append(ths.whereFragments, rawExprs(where)...)
+
+	return ths
+}
+
+// AddWhereExpr adds where clause with bound arguments in generated query.
+func (ths *queryBuilder) AddWhereExpr(where ...Expr) *queryBuilder {
 	ths.whereFragments = append(ths.whereFragments, where...)
 
 	return ths
@@ -65,13 +110,43 @@ func (ths *queryBuilder) AddWhere(where ...string) *queryBuilder {
 
 // AddGroupBy adds field to group by in generated query.
 func (ths *queryBuilder) AddGroupBy(groupBy ...string) *queryBuilder {
-	ths.groupByFragments = append(ths.groupByFragments, groupBy...)
+	ths.groupByFragments = append(ths.groupByFragments, rawExprs(groupBy)...)
+
+	return ths
+}
+
+// AddHaving adds having clause to include in generated query.
+func (ths *queryBuilder) AddHaving(having ...string) *queryBuilder {
+	ths.havingFragments = append(ths.havingFragments, rawExprs(having)...)
+
+	return ths
+}
+
+// AddHavingExpr adds having clause with bound arguments to include in generated query.
+func (ths *queryBuilder) AddHavingExpr(having ...Expr) *queryBuilder {
+	ths.havingFragments = append(ths.havingFragments, having...)
+
+	return ths
+}
+
+// AddWindow adds named window definition to WINDOW clause, functions refer to it using
+// OverWindow or base other windows on it using From.
+func (ths *queryBuilder) AddWindow(name string, window *WindowSpec) *queryBuilder {
+	ths.windowFragments = append(ths.windowFragments, formatExpr("%s AS %s", rawExpr(name), window.Expr()))
 
 	return ths
 }
 
 // AddOrderBy adds field to order by in generated query.
 func (ths *queryBuilder) AddOrderBy(orderBy ...string) *queryBuilder {
+	ths.orderByFragments = append(ths.orderByFragments, rawExprs(orderBy)...)
+
+	return ths
+}
+
+// AddOrderByExpr adds field with bound arguments to order by in generated query, e.g. rank of
+// full-text search.
+func (ths *queryBuilder) AddOrderByExpr(orderBy ...Expr) *queryBuilder {
 	ths.orderByFragments = append(ths.orderByFragments, orderBy...)
 
 	return ths
@@ -91,71 +166,425 @@ func (ths *queryBuilder) AddOffset(offset int32) *queryBuilder {
 	return ths
 }
 
+// ForUpdate locks selected rows against update, delete and other locks.
+func (ths *queryBuilder) ForUpdate() *queryBuilder {
+	return ths.addLock(lockUpdate)
+}
+
+// ForNoKeyUpdate locks selected rows like ForUpdate but lets concurrent ForKeyShare locks through,
+// mysql renders it as FOR UPDATE.
+func (ths *queryBuilder) ForNoKeyUpdate() *queryBuilder {
+	return ths.addLock(lockNoKeyUpdate)
+}
+
+// ForShare locks selected rows against update and delete while allowing other shared locks.
+func (ths *queryBuilder) ForShare() *queryBuilder {
+	return ths.addLock(lockShare)
+}
+
+// ForKeyShare locks selected rows against delete and key update, mysql renders it as FOR SHARE.
+func (ths *queryBuilder) ForKeyShare() *queryBuilder {
+	return ths.addLock(lockKeyShare)
+}
+
+// Of restricts last locking clause to rows of the tables.
+func (ths *queryBuilder) Of(tables ...string) *queryBuilder {
+	lock, err := lastLock(ths.locks, "Of")
+	if err != nil {
+		ths.err = err
+
+		return ths
+	}
+
+	lock.of = append(lock.of, tables...)
+
+	return ths
+}
+
+// SkipLocked makes last locking clause skip rows that can't be locked immediately.
+func (ths *queryBuilder) SkipLocked() *queryBuilder {
+	return ths.setLockWait(lockSkipLocked, "SkipLocked")
+}
+
+// NoWait makes last locking clause fail instead of waiting for rows that can't be locked
+// immediately.
+func (ths *queryBuilder) NoWait() *queryBuilder {
+	return ths.setLockWait(lockNoWait, "NoWait")
+}
+
+func (ths *queryBuilder) addLock(strength lockStrength) *queryBuilder {
+	ths.locks = append(ths.locks, rowLock{strength: strength})
+
+	return ths
+}
+
+func (ths *queryBuilder) setLockWait(wait lockWait, option string) *queryBuilder {
+	lock, err := lastLock(ths.locks, option)
+	if err == nil {
+		err = lock.setWait(wait)
+	}
+	if err != nil {
+		ths.err = err
+	}
+
+	return ths
+}
+
+// WithDeleted includes soft deleted rows in generated query.
+func (ths *queryBuilder) WithDeleted() *queryBuilder {
+	ths.softDeleteScope = includeDeleted
+
+	return ths
+}
+
+// OnlyDeleted limits generated query to soft deleted rows of tables it selects from, joined tables
+// still exclude their soft deleted rows.
+func (ths *queryBuilder) OnlyDeleted() *queryBuilder {
+	ths.softDeleteScope = onlyDeleted
+
+	return ths
+}
+
+// WithSchema binds schema to validate tables and columns of generated query against.
+func (ths *queryBuilder) WithSchema(schema *Schema) *queryBuilder {
+	ths.schema = schema
+
+	return ths
+}
+
+// WithoutAutoParenthesis joins where and having fragments as they are, by default fragments having top-level
+// OR are wrapped in parentheses so they don't weaken conditions they are joined with using AND.
+func (ths *queryBuilder) WithoutAutoParenthesis() *queryBuilder {
+	ths.rawConditions = true
+
+	return ths
+}
+
+// WithLint enables lint pass over raw fragments of generated query.
+func (ths *queryBuilder) WithLint(options LintOptions) *queryBuilder {
+	ths.lint = &options
+
+	return ths
+}
+
+// WithDialect sets sql dialect of generated query.
+func (ths *queryBuilder) WithDialect(dialect Dialect) *queryBuilder {
+	ths.options.dialect = dialect
+
+	return ths
+}
+
+// WithArrayBinding binds slice of IN (?) condition as single array argument using = ANY(?),
+// it requires postgres dialect.
+func (ths *queryBuilder) WithArrayBinding() *queryBuilder {
+	ths.options.arrayBinding = true
+
+	return ths
+}
+
+// WithFormat sets layout of generated query.
+func (ths *queryBuilder) WithFormat(format Format) *queryBuilder {
+	ths.options.format = format
+
+	return ths
+}
+
+// When calls apply with the builder only when condition is true, keeping the chain unbroken.
+func (ths *queryBuilder) When(condition bool, apply func(builder *queryBuilder)) *queryBuilder {
+	if condition {
+		apply(ths)
+	}
+
+	return ths
+}
+
+// AddWhereIf adds where clause in generated query only when condition is true.
+func (ths *queryBuilder) AddWhereIf(condition bool, where ...string) *queryBuilder {
+	if condition {
+		ths.AddWhere(where...)
+	}
+
+	return ths
+}
+
+// AddWhereIfNotZero adds where clause binding value to its placeholders only when value is not
+// nil nor zero value of its type.
+func (ths *queryBuilder) AddWhereIfNotZero(value interface{}, where string) *queryBuilder {
+	if !isZeroValue(value) {
+		ths.AddWhereExpr(bindEach(where, value))
+	}
+
+	return ths
+}
+
+// AddWhereFilter adds where clause for each field of filter struct tagged with sq, e.g.
+// `sq:"status = ?"`, skipping nil and zero value fields.
+func (ths *queryBuilder) AddWhereFilter(filter interface{}) *queryBuilder {
+	conditions, err := filterConditions(filter)
+	if err != nil {
+		ths.err = err
+
+		return ths
+	}
+
+	return ths.AddWhereExpr(conditions...)
+}
+
 // BuildQuery generates final query string.
 func (ths *queryBuilder) BuildQuery() (string, error) {
+	query, _, err := ths.BuildQueryWithArgs()
+
+	return query, err
+}
+
+// BuildQueryWithArgs generates final query string and arguments bound to its placeholders.
+func (ths *queryBuilder) BuildQueryWithArgs() (string, []interface{}, error) {
+	expr, err := ths.buildExpr()
+	if err != nil {
+		return "", nil, err
+	}
+
+	return renderExpr(expr, ths.options)
+}
+
+// DebugString returns query with arguments inlined as escaped literals for logs, it must never be
+// executed.
+func (ths *queryBuilder) DebugString() string {
+	return debugString(ths)
+}
+
+func (ths *queryBuilder) buildExpr() (Expr, error) {
+	if ths.err != nil {
+		return Expr{}, ths.err
+	}
+	clauses := ths.clauses()
+	if err := checkFragments(clauses...); err != nil {
+		return Expr{}, err
+	}
+	if err := lint(ths.lint, !ths.rawConditions, clauses...); err != nil {
+		return Expr{}, err
+	}
 	if len(ths.fromFragments) == 0 {
-		return "", errors.New("no table specified for query")
+		return Expr{}, errors.New("no table specified for query")
 	}
 	if len(ths.selectFragments) == 0 {
-		return "", errors.New("no field selected, add it using AddSelect method")
+		return Expr{}, errors.New("no field selected, add it using AddSelect method")
+	}
+	if len(ths.distinctOn) > 0 {
+		if ths.distinct {
+			return Expr{}, errors.New("distinct and distinct on can't be used together")
+		}
+		if !ths.options.dialect.allows(Postgres) {
+			return Expr{}, ths.options.dialect.unsupported("DISTINCT ON")
+		}
+		if err := validateDistinctOn(ths.distinctOn, ths.orderByFragments); err != nil {
+			return Expr{}, err
+		}
+	}
+	if len(ths.relations) > 0 && !ths.options.dialect.allows(Postgres) {
+		return Expr{}, ths.options.dialect.unsupported("WithMany")
+	}
+	if ths.schema != nil {
+		if err := ths.validateSchema(); err != nil {
+			return Expr{}, err
+		}
+	}
+
+	selectFragments := append([]Expr{}, ths.selectFragments...)
+
+	for _, relation := range ths.relations {
+		expr, err := relation.expr()
+		if err != nil {
+			return Expr{}, err
+		}
+
+		selectFragments = append(selectFragments, expr)
+	}
+
+	joinFragments, softDeleteJoinConditions, err := softDeleteJoins(ths.joinFragments, ths.softDeleteScope)
+	if err != nil {
+		return Expr{}, err
 	}
 
-	queryFragments := []string{}
+	queryFragments := []Expr{}
 
 	if len(ths.cteFragments) > 0 {
-		queryFragments = append(queryFragments, fmt.Sprintf(
+		queryFragments = append(queryFragments, formatExpr(
 			"WITH %s",
-			strings.Join(ths.cteFragments, ", "),
+			joinExprs(ths.cteFragments, ", "),
 		))
 	}
 
-	queryFragments = append(queryFragments, fmt.Sprintf(
-		"SELECT %s FROM %s",
-		strings.Join(ths.selectFragments, ", "),
-		strings.Join(ths.fromFragments, ", "),
+	selectExpr := rawExpr("SELECT")
+
+	if ths.distinct {
+		selectExpr = rawExpr("SELECT DISTINCT")
+	}
+
+	if len(ths.distinctOn) > 0 {
+		selectExpr = formatExpr(
+			"SELECT DISTINCT ON (%s)",
+			joinExprs(ths.distinctOn, ", "),
+		)
+	}
+
+	queryFragments = append(queryFragments, formatExpr(
+		"%s %s FROM %s",
+		selectExpr,
+		joinExprs(selectFragments, ", "),
+		joinExprs(ths.fromFragments, ", "),
 	))
 
-	if len(ths.joinFragments) > 0 {
-		queryFragments = append(queryFragments, strings.Join(ths.joinFragments, " "))
+	if len(joinFragments) > 0 {
+		queryFragments = append(queryFragments, joinExprs(joinFragments, " "))
+	}
+
+	whereFragments := ths.whereFragments
+	havingFragments := ths.havingFragments
+
+	if !ths.rawConditions {
+		whereFragments = parenthesizeOr(whereFragments, ths.options.dialect)
+		havingFragments = parenthesizeOr(havingFragments, ths.options.dialect)
 	}
 
-	if len(ths.whereFragments) > 0 {
-		queryFragments = append(queryFragments, fmt.Sprintf(
+	guards := rawExprs(softDeleteConditions(exprSQLs(ths.fromFragments), ths.softDeleteScope))
+	guards = append(guards, softDeleteJoinConditions...)
+	whereFragments = guardConditions(whereFragments, append(guards, ths.guards...), ths.options.dialect)
+
+	if len(whereFragments) > 0 {
+		queryFragments = append(queryFragments, formatExpr(
 			"WHERE %s",
-			strings.Join(ths.whereFragments, " AND "),
+			joinExprs(whereFragments, " AND "),
 		))
 	}
 
 	if len(ths.groupByFragments) > 0 {
-		queryFragments = append(queryFragments, fmt.Sprintf(
+		queryFragments = append(queryFragments, formatExpr(
 			"GROUP BY %s",
-			strings.Join(ths.groupByFragments, ", "),
+			joinExprs(ths.groupByFragments, ", "),
+		))
+	}
+
+	if len(havingFragments) > 0 {
+		queryFragments = append(queryFragments, formatExpr(
+			"HAVING %s",
+			joinExprs(havingFragments, " AND "),
+		))
+	}
+
+	if len(ths.windowFragments) > 0 {
+		queryFragments = append(queryFragments, formatExpr(
+			"WINDOW %s",
+			joinExprs(ths.windowFragments, ", "),
 		))
 	}
 
 	if len(ths.orderByFragments) > 0 {
-		queryFragments = append(queryFragments, fmt.Sprintf(
+		queryFragments = append(queryFragments, formatExpr(
 			"ORDER BY %s",
-			strings.Join(ths.orderByFragments, ", "),
+			joinExprs(ths.orderByFragments, ", "),
 		))
 	}
 
 	if ths.limit != nil {
-		queryFragments = append(queryFragments, fmt.Sprintf(
+		queryFragments = append(queryFragments, rawExpr(fmt.Sprintf(
 			"LIMIT %s",
 			fmt.Sprint(*ths.limit),
-		))
+		)))
 	}
 
 	if ths.offset != nil {
-		queryFragments = append(queryFragments, fmt.Sprintf(
+		queryFragments = append(queryFragments, rawExpr(fmt.Sprintf(
 			"OFFSET %s",
 			fmt.Sprint(*ths.offset),
-		))
+		)))
 	}
 
-	query := strings.Join(queryFragments, " ")
-	query = whitespaceNormalizer.ReplaceAllString(query, " ")
+	for _, lock := range ths.locks {
+		clause, err := lock.render(ths.options.dialect)
+		if err != nil {
+			return Expr{}, err
+		}
+
+		queryFragments = append(queryFragments, rawExpr(clause))
+	}
+
+	return joinExprs(queryFragments, " "), nil
+}
+
+func (ths *queryBuilder) buildOptions() renderOptions {
+	return ths.options
+}
+
+func (ths *queryBuilder) shape() Shape {
+	table := ""
+	if len(ths.fromFragments) > 0 {
+		table = shapeTable(exprSQLs(ths.fromFragments)[0])
+	}
+
+	return Shape{
+		Kind:       SelectStatement,
+		Table:      table,
+		Conditions: shapeConditions(ths.whereFragments),
+	}
+}
+
+// clauses returns fragments of every clause to check.
+func (ths *queryBuilder) clauses() []clauseFragments {
+	lockedTables := []Expr{}
+	for _, lock := range ths.locks {
+		lockedTables = append(lockedTables, rawExprs(lock.of)...)
+	}
+
+	return []clauseFragments{
+		{clause: "cte", fragments: ths.cteFragments},
+		{clause: "select", fragments: ths.selectFragments},
+		{clause: "distinct on", fragments: ths.distinctOn},
+		{clause: "from", fragments: ths.fromFragments},
+		{clause: "join", fragments: ths.joinFragments},
+		{clause: "where", fragments: ths.whereFragments},
+		{clause: "group by", fragments: ths.groupByFragments},
+		{clause: "having", fragments: ths.havingFragments},
+		{clause: "order by", fragments: ths.orderByFragments},
+		{clause: "lock of", fragments: lockedTables},
+	}
+}
+
+// validateSchema checks tables and simple column fragments against bound schema.
+func (ths *queryBuilder) validateSchema() error {
+	scope := newSchemaScope(ths.schema)
+	scope.addCTEs(exprSQLs(ths.cteFragments))
+
+	for _, table := range exprSQLs(ths.fromFragments) {
+		if err := scope.addTable(table); err != nil {
+			return err
+		}
+	}
+
+	if err := scope.addJoins(exprSQLs(ths.joinFragments)); err != nil {
+		return err
+	}
+
+	fields := []string{}
+	for _, field := range exprSQLs(ths.selectFragments) {
+		if match := selectAliasPattern.FindStringSubmatchIndex(field); match != nil {
+			scope.aliases[field[match[2]:match[3]]] = true
+			field = field[:match[0]]
+		}
+
+		fields = append(fields, field)
+	}
+
+	orderBy := []string{}
+	for _, field := range exprSQLs(ths.orderByFragments) {
+		orderBy = append(orderBy, orderDirectionPattern.ReplaceAllString(field, ""))
+	}
+
+	for _, fragments := range [][]string{fields, exprSQLs(ths.groupByFragments), orderBy} {
+		if err := scope.validateColumns(fragments); err != nil {
+			return err
+		}
+	}
 
-	return query, nil
+	return nil
 }
@@ -369,4 +369,69 @@ func TestNewReadQuery(t *testing.T) {
 			So(query, ShouldEqual, "WITH table_expression_a AS (SELECT 0) SELECT field_a FROM table_a LEFT JOIN table_b ON table_b.id = table_a.id WHERE table_a.id = :id GROUP BY id ORDER BY field_a ASC LIMIT 10 OFFSET 5")
 		})
 	})
+
+	Convey("Given where and having fragments with top-level OR", t, func() {
+		RegisterSoftDelete("table_b", SoftDelete{Column: "deleted_at"})
+		defer UnregisterSoftDelete("table_b")
+
+		query, err := NewReadQuery("table_a").
+			AddSelect("field_a").
+			AddWhere("field_a = 'A' OR field_a = 'B'", "(field_b = 1 OR field_b = 2)", "field_c = 'x OR y'").
+			AddGroupBy("field_a").
+			AddHaving("COUNT(*) > 1 OR SUM(field_b) > 2", "MIN(field_b) > 0").
+			BuildQuery()
+		single, singleErr := NewReadQuery("table_a").
+			AddSelect("field_a").
+			AddWhere("field_a = 'A' OR field_a = 'B'").
+			BuildQuery()
+		softDeleted, softDeletedErr := NewReadQuery("table_b").
+			AddSelect("field_a").
+			AddWhere("field_a = 'A' OR field_a = 'B'").
+			BuildQuery()
+		raw, rawErr := NewReadQuery("table_a").
+			WithoutAutoParenthesis().
+			AddSelect("field_a").
+			AddWhere("field_a = 'A' OR field_a = 'B'", "field_c IS NULL").
+			BuildQuery()
+
+		Convey("It should wrap fragments joined with other conditions in parentheses unless disabled", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT field_a FROM table_a WHERE (field_a = 'A' OR field_a = 'B') AND (field_b = 1 OR field_b = 2) AND field_c = 'x OR y' GROUP BY field_a HAVING (COUNT(*) > 1 OR SUM(field_b) > 2) AND MIN(field_b) > 0")
+			So(singleErr, ShouldBeNil)
+			So(single, ShouldEqual, "SELECT field_a FROM table_a WHERE field_a = 'A' OR field_a = 'B'")
+			So(softDeletedErr, ShouldBeNil)
+			So(softDeleted, ShouldEqual, "SELECT field_a FROM table_b WHERE (field_a = 'A' OR field_a = 'B') AND table_b.deleted_at IS NULL")
+			So(rawErr, ShouldBeNil)
+			So(raw, ShouldEqual, "SELECT field_a FROM table_a WHERE field_a = 'A' OR field_a = 'B' AND field_c IS NULL")
+		})
+	})
+
+	Convey("Given mysql where fragments with top-level OR written as escaped literal, || or XOR", t, func() {
+		RegisterSoftDelete("table_b", SoftDelete{Column: "deleted_at"})
+		defer UnregisterSoftDelete("table_b")
+
+		mysql, mysqlErr := NewReadQuery("table_b").
+			WithDialect(MySQL).
+			AddSelect("field_a").
+			AddWhere(`name = 'it\'s' OR role = 'admin'`, "a = 1 || b = 2", "a = 1 XOR b = 2", "field_c = 3").
+			BuildQuery()
+		postgres, postgresErr := NewReadQuery("table_a").
+			WithDialect(Postgres).
+			AddSelect("field_a").
+			AddWhere("name || 'x' = 'ax'", "field_c = 3").
+			BuildQuery()
+		standard, standardErr := NewReadQuery("table_a").
+			AddSelect("field_a").
+			AddWhere(`name = 'it\'s' OR role = 'admin'`, "field_c = 3").
+			BuildQuery()
+
+		Convey("It should wrap them in parentheses, and fragment the dialect can't tokenize as well", func() {
+			So(mysqlErr, ShouldBeNil)
+			So(mysql, ShouldEqual, `SELECT field_a FROM table_b WHERE ((name = 'it\'s' OR role = 'admin') AND (a = 1 || b = 2) AND (a = 1 XOR b = 2) AND field_c = 3) AND table_b.deleted_at IS NULL`)
+			So(postgresErr, ShouldBeNil)
+			So(postgres, ShouldEqual, "SELECT field_a FROM table_a WHERE name || 'x' = 'ax' AND field_c = 3")
+			So(standardErr, ShouldBeNil)
+			So(standard, ShouldEqual, `SELECT field_a FROM table_a WHERE (name = 'it\'s' OR role = 'admin') AND field_c = 3`)
+		})
+	})
 }
@@ -0,0 +1,93 @@
+package squbix
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+)
+
+// relationNamePattern matches unquoted identifier, relation name is used as alias and column name.
+var relationNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// relation is rows of child read query loaded with each row of parent query as json array.
+type relation struct {
+	name  string
+	child *queryBuilder
+	on    string
+}
+
+// WithMany selects rows of child read query related to each row of the query as json array named
+// name, on is condition correlating child rows to the parent row, e.g. "order_id = orders.id".
+// Name must be unquoted identifier. Order by of child query orders the array, so it may refer only
+// to fields the child query selects. Child query may have relations of its own, the array is
+// decoded using ScanJSON. It requires postgres dialect.
+func (ths *queryBuilder) WithMany(name string, child *queryBuilder, on string) *queryBuilder {
+	ths.relations = append(ths.relations, relation{
+		name:  name,
+		child: child,
+		on:    on,
+	})
+
+	return ths
+}
+
+// expr returns correlated subquery aggregating child rows into json array, keys of its objects
+// are fields selected by child query. Order of derived table rows isn't kept by aggregation, so
+// order by of child query moves into the aggregate, it stays in child query too when limit, offset
+// or distinct on depends on it.
+func (ths relation) expr() (Expr, error) {
+	if !relationNamePattern.MatchString(ths.name) {
+		return Expr{}, fmt.Errorf("relation name %q isn't unquoted identifier", ths.name)
+	}
+
+	child := *ths.child
+	child.guards = append(append([]Expr{}, ths.child.guards...), rawExpr(ths.on))
+
+	if child.limit == nil && child.offset == nil && len(child.distinctOn) == 0 {
+		child.orderByFragments = nil
+	}
+
+	expr, err := child.buildExpr()
+	if err != nil {
+		return Expr{}, fmt.Errorf("relation %s: %s", ths.name, err)
+	}
+
+	aggregated := rawExpr(ths.name)
+	if len(ths.child.orderByFragments) > 0 {
+		aggregated = formatExpr("%s ORDER BY %s", aggregated, joinExprs(ths.child.orderByFragments, ", "))
+	}
+
+	return postgresJSON(formatExpr(
+		"(SELECT COALESCE(JSON_AGG(%s), '[]'::json) FROM (%s) AS %s) AS %s",
+		aggregated,
+		expr,
+		rawExpr(ths.name),
+		rawExpr(ths.name),
+	)), nil
+}
+
+// ScanJSON returns scanner decoding json column, e.g. array selected by WithMany, into value
+// target points to. Struct fields are matched to keys by json tags or case-insensitive names, null
+// leaves the value as is.
+func ScanJSON(target interface{}) sql.Scanner {
+	return jsonScanner{target: target}
+}
+
+type jsonScanner struct {
+	target interface{}
+}
+
+// Scan decodes json text or bytes into target.
+func (ths jsonScanner) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(src, ths.target)
+	case string:
+		return json.Unmarshal([]byte(src), ths.target)
+	}
+
+	return fmt.Errorf("can't decode json from %T", src)
+}
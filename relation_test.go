@@ -0,0 +1,171 @@
+package squbix
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWithMany(t *testing.T) {
+	Convey("Given read query with nested relations", t, func() {
+		shipments := NewReadQuery("shipments").
+			AddSelect("id", "carrier").
+			AddWhereExpr(NewExpr("carrier <> ?", "void"))
+		items := NewReadQuery("items").
+			AddSelect("id", "sku").
+			AddWhereExpr(NewExpr("quantity > ?", 0)).
+			AddOrderBy("id").
+			WithMany("shipments", shipments, "item_id = items.id")
+
+		query, args, err := NewReadQuery("orders").
+			WithDialect(Postgres).
+			AddSelect("id").
+			WithMany("items", items, "order_id = orders.id").
+			AddWhereExpr(NewExpr("status = ?", "paid")).
+			BuildQueryWithArgs()
+
+		Convey("It should select each relation as json array of correlated subquery", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT id, (SELECT COALESCE(JSON_AGG(items ORDER BY id), '[]'::json) FROM ("+
+				"SELECT id, sku, (SELECT COALESCE(JSON_AGG(shipments), '[]'::json) FROM ("+
+				"SELECT id, carrier FROM shipments WHERE (carrier <> $1) AND item_id = items.id"+
+				") AS shipments) AS shipments FROM items WHERE (quantity > $2) AND order_id = orders.id"+
+				") AS items) AS items FROM orders WHERE status = $3")
+			So(args, ShouldResemble, []interface{}{"void", 0, "paid"})
+		})
+
+		Convey("It should leave child queries and derived count query without relations", func() {
+			child, childErr := items.BuildQuery()
+			count, countErr := NewReadQuery("orders").
+				AddSelect("id").
+				WithMany("items", items, "order_id = orders.id").
+				CountQuery().
+				BuildQuery()
+
+			So(childErr, ShouldBeNil)
+			So(child, ShouldNotContainSubstring, "order_id = orders.id")
+			So(countErr, ShouldBeNil)
+			So(count, ShouldEqual, "SELECT COUNT(*) FROM orders")
+		})
+	})
+
+	Convey("Given relation whose child query has top-level OR without auto parenthesis", t, func() {
+		query, err := NewReadQuery("orders").
+			WithDialect(Postgres).
+			AddSelect("id").
+			WithMany("items", NewReadQuery("items").
+				WithoutAutoParenthesis().
+				AddSelect("id").
+				AddWhere("status = 'new' OR status = 'paid'"), "order_id = orders.id").
+			BuildQuery()
+
+		Convey("It should keep the relation condition outside of the OR", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT id, (SELECT COALESCE(JSON_AGG(items), '[]'::json) FROM "+
+				"(SELECT id FROM items WHERE (status = 'new' OR status = 'paid') AND order_id = orders.id) AS items) AS items FROM orders")
+		})
+	})
+
+	Convey("Given relation whose child query orders and limits rows", t, func() {
+		query, args, err := NewReadQuery("customers").
+			WithDialect(Postgres).
+			AddSelect("id").
+			WithMany("orders", NewReadQuery("orders").
+				AddSelect("id", "total").
+				AddOrderByExpr(NewExpr("total <> ?", 0), NewExpr("id DESC")).
+				AddLimit(3), "customer_id = customers.id").
+			BuildQueryWithArgs()
+
+		Convey("It should order the aggregate and keep order by choosing limited rows", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT id, (SELECT COALESCE(JSON_AGG(orders ORDER BY total <> $1, id DESC), '[]'::json) FROM ("+
+				"SELECT id, total FROM orders WHERE customer_id = customers.id ORDER BY total <> $2, id DESC LIMIT 3"+
+				") AS orders) AS orders FROM customers")
+			So(args, ShouldResemble, []interface{}{0, 0})
+		})
+	})
+
+	Convey("Given relations with invalid child query or unsupported dialect", t, func() {
+		_, childErr := NewReadQuery("orders").
+			AddSelect("id").
+			WithMany("items", NewReadQuery("items"), "order_id = orders.id").
+			BuildQuery()
+		_, dialectErr := NewReadQuery("orders").
+			WithDialect(MySQL).
+			AddSelect("id").
+			WithMany("items", NewReadQuery("items").AddSelect("id"), "order_id = orders.id").
+			BuildQuery()
+		_, nameErr := NewReadQuery("orders").
+			WithDialect(Postgres).
+			AddSelect("id").
+			WithMany("items) AS x, (SELECT 1", NewReadQuery("items").AddSelect("id"), "order_id = orders.id").
+			BuildQuery()
+
+		Convey("It should returns error", func() {
+			So(nameErr, ShouldBeError, `relation name "items) AS x, (SELECT 1" isn't unquoted identifier`)
+			So(childErr, ShouldNotBeNil)
+			So(childErr.Error(), ShouldEqual, "relation items: no field selected, add it using AddSelect method")
+			So(dialectErr, ShouldNotBeNil)
+			So(dialectErr.Error(), ShouldEqual, "WithMany is not supported by mysql dialect")
+		})
+	})
+
+	Convey("Given relation expression built in mysql dialect", t, func() {
+		expr, err := relation{
+			name:  "items",
+			child: NewReadQuery("items").AddSelect("id"),
+			on:    "order_id = orders.id",
+		}.expr()
+		So(err, ShouldBeNil)
+
+		_, mysqlErr := NewReadQuery("orders").WithDialect(MySQL).AddSelectExpr(expr).BuildQuery()
+
+		Convey("It should returns error of unsupported dialect", func() {
+			So(mysqlErr, ShouldBeError, "json operators is not supported by mysql dialect")
+		})
+	})
+}
+
+func TestScanJSON(t *testing.T) {
+	type shipment struct {
+		ID      int64  `json:"id"`
+		Carrier string `json:"carrier"`
+	}
+	type item struct {
+		ID        int64      `json:"id"`
+		SKU       string     `json:"sku"`
+		Shipments []shipment `json:"shipments"`
+	}
+
+	Convey("Given json array of nested relations", t, func() {
+		items := []item{}
+		err := ScanJSON(&items).Scan([]byte(`[{"id": 1, "sku": "A-1", "shipments": [{"id": 7, "carrier": "dhl"}]}, {"id": 2, "sku": "B-2", "shipments": []}]`))
+
+		Convey("It should decode it into nested structs", func() {
+			So(err, ShouldBeNil)
+			So(items, ShouldResemble, []item{
+				{ID: 1, SKU: "A-1", Shipments: []shipment{{ID: 7, Carrier: "dhl"}}},
+				{ID: 2, SKU: "B-2", Shipments: []shipment{}},
+			})
+		})
+	})
+
+	Convey("Given null, text and unsupported values", t, func() {
+		items := []item{{ID: 3}}
+		nullErr := ScanJSON(&items).Scan(nil)
+
+		names := []string{}
+		textErr := ScanJSON(&names).Scan(`["a", "b"]`)
+
+		numberErr := ScanJSON(&names).Scan(int64(1))
+
+		Convey("It should keep value on null, decode text and reject other types", func() {
+			So(nullErr, ShouldBeNil)
+			So(items, ShouldResemble, []item{{ID: 3}})
+			So(textErr, ShouldBeNil)
+			So(names, ShouldResemble, []string{"a", "b"})
+			So(numberErr, ShouldNotBeNil)
+			So(numberErr.Error(), ShouldEqual, "can't decode json from int64")
+		})
+	})
+}
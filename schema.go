@@ -0,0 +1,434 @@
+package squbix
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const defaultPrimaryKeySuffix = "_pkey"
+
+var (
+	orderDirectionPattern = regexp.MustCompile(`(?i)(\s+(ASC|DESC))?(\s+NULLS\s+(FIRST|LAST))?\s*$`)
+	selectAliasPattern    = regexp.MustCompile(`(?i)\s+AS\s+([A-Za-z_][A-Za-z0-9_]*|"(?:[^"]|"")+"|` + "`(?:[^`]|``)+`" + `)\s*$`)
+)
+
+// Schema describes tables that builders can validate generated query against.
+type Schema struct {
+	tables map[string]Table
+}
+
+// Table describes a table and its columns.
+type Table struct {
+	Name              string       `json:"name"`
+	Columns           []Column     `json:"columns"`
+	PrimaryKey        Constraint   `json:"primary_key"`
+	UniqueConstraints []Constraint `json:"unique_constraints,omitempty"`
+}
+
+// Column describes a table column.
+type Column struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Nullable bool   `json:"nullable"`
+}
+
+// Constraint describes primary key or unique constraint of a table.
+type Constraint struct {
+	Name    string   `json:"name,omitempty"`
+	Columns []string `json:"columns"`
+}
+
+type schemaJSON struct {
+	Tables []Table `json:"tables"`
+}
+
+// NewSchema creates new schema registry containing the tables.
+func NewSchema(tables ...Table) *Schema {
+	schema := &Schema{
+		tables: map[string]Table{},
+	}
+
+	return schema.AddTable(tables...)
+}
+
+// AddTable adds table to the schema, replacing any table with the same name.
+func (ths *Schema) AddTable(tables ...Table) *Schema {
+	for _, table := range tables {
+		ths.tables[table.Name] = table
+	}
+
+	return ths
+}
+
+// Table returns table by its name, name may be qualified with database schema e.g. public.users.
+// Unquoted names are compared case insensitively and quoted ones, e.g. "Users", exactly.
+func (ths *Schema) Table(name string) (Table, bool) {
+	if table, ok := ths.tables[name]; ok {
+		return table, true
+	}
+
+	reference, ok := parseIdentifier(name)
+	if !ok {
+		return Table{}, false
+	}
+
+	for _, table := range ths.Tables() {
+		if sameTable(reference, nameIdentifiers(table.Name)) {
+			return table, true
+		}
+	}
+
+	return Table{}, false
+}
+
+// Tables returns all tables in the schema ordered by name.
+func (ths *Schema) Tables() []Table {
+	tables := make([]Table, 0, len(ths.tables))
+	for _, table := range ths.tables {
+		tables = append(tables, table)
+	}
+
+	sort.Slice(tables, func(i, j int) bool {
+		return tables[i].Name < tables[j].Name
+	})
+
+	return tables
+}
+
+// MarshalJSON encodes schema as object holding list of tables ordered by name.
+func (ths *Schema) MarshalJSON() ([]byte, error) {
+	return json.Marshal(schemaJSON{Tables: ths.Tables()})
+}
+
+// UnmarshalJSON decodes schema encoded by MarshalJSON.
+func (ths *Schema) UnmarshalJSON(data []byte) error {
+	decoded := schemaJSON{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	ths.tables = map[string]Table{}
+	ths.AddTable(decoded.Tables...)
+
+	return nil
+}
+
+// Column returns column by its name. Unquoted name is compared case insensitively and quoted one
+// exactly.
+func (ths Table) Column(name string) (Column, bool) {
+	for _, column := range ths.Columns {
+		if column.Name == name {
+			return column, true
+		}
+	}
+
+	reference, ok := parseIdentifier(name)
+	if !ok || len(reference) != 1 {
+		return Column{}, false
+	}
+
+	return ths.column(reference[0])
+}
+
+// column returns column the identifier refers to.
+func (ths Table) column(reference identifier) (Column, bool) {
+	for _, column := range ths.Columns {
+		if reference.matches(identifier{name: column.Name}) {
+			return column, true
+		}
+	}
+
+	return Column{}, false
+}
+
+// nameIdentifiers returns parts of table name as written in schema, name that isn't identifier is
+// single part.
+func nameIdentifiers(name string) []identifier {
+	if parts, ok := parseIdentifier(name); ok {
+		return parts
+	}
+
+	return []identifier{{name: name}}
+}
+
+// primaryKeyName returns primary key constraint name, defaults to postgres naming convention.
+func (ths Table) primaryKeyName() string {
+	if len(ths.PrimaryKey.Name) > 0 {
+		return ths.PrimaryKey.Name
+	}
+
+	return ths.Name + defaultPrimaryKeySuffix
+}
+
+// schemaScope holds tables a query refers to, keyed by the name used to refer to them.
+// Scope is opaque when query also refers to relations it can't resolve, e.g. subqueries or
+// common table expressions.
+type schemaScope struct {
+	schema  *Schema
+	tables  map[string]Table
+	ctes    map[string]bool
+	aliases map[string]bool
+	opaque  bool
+}
+
+func newSchemaScope(schema *Schema) *schemaScope {
+	return &schemaScope{
+		schema:  schema,
+		tables:  map[string]Table{},
+		ctes:    map[string]bool{},
+		aliases: map[string]bool{},
+	}
+}
+
+// addCTEs registers names of common table expressions defined by cte fragments, so tables can
+// refer to them.
+func (ths *schemaScope) addCTEs(fragments []string) {
+	for _, fragment := range fragments {
+		tokens, err := tokenize(fragment)
+		if err != nil {
+			continue
+		}
+
+		for _, cte := range splitTokens(tokens, ",") {
+			if len(cte) > 0 && cte[0].is("RECURSIVE") {
+				cte = cte[1:]
+			}
+
+			if len(cte) > 0 && isIdentifierToken(cte[0]) {
+				ths.ctes[cte[0].text] = true
+			}
+		}
+	}
+}
+
+// addTable registers table fragment into the scope. Fragments which are not plain table
+// reference, e.g. subqueries, and references to common table expressions are ignored.
+func (ths *schemaScope) addTable(fragment string) error {
+	name, alias := splitTableAlias(fragment)
+
+	reference, ok := parseIdentifier(name)
+	if !ok || len(reference) > 2 || (len(reference) == 1 && ths.isCTE(reference[0])) {
+		ths.opaque = true
+
+		return nil
+	}
+
+	table, ok := ths.schema.Table(name)
+	if !ok {
+		return fmt.Errorf("unknown table %q", name)
+	}
+
+	ths.tables[alias] = table
+
+	return nil
+}
+
+// isCTE reports whether name refers to common table expression of the scope.
+func (ths *schemaScope) isCTE(name identifier) bool {
+	for cte := range ths.ctes {
+		if parts, ok := parseIdentifier(cte); ok && name.matches(parts[0]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// table returns table of the scope its alias, or name when it has no alias, refers to.
+func (ths *schemaScope) table(qualifier identifier) (Table, bool) {
+	if table, ok := ths.tables[qualifier.name]; ok && !qualifier.quoted {
+		return table, true
+	}
+
+	for alias, table := range ths.tables {
+		if parts, ok := parseIdentifier(alias); ok && len(parts) == 1 && qualifier.matches(parts[0]) {
+			return table, true
+		}
+	}
+
+	return Table{}, false
+}
+
+// addJoins registers tables joined by join fragments into the scope, joined subqueries make the
+// scope opaque.
+func (ths *schemaScope) addJoins(fragments []string) error {
+	for _, fragment := range fragments {
+		tokens, err := tokenize(fragment)
+		if err != nil {
+			ths.opaque = true
+
+			continue
+		}
+
+		for index, current := range tokens {
+			if !current.is("JOIN") {
+				continue
+			}
+
+			name, alias, _ := joinedTable(tokens, index, len(tokens))
+			if len(name) == 0 {
+				ths.opaque = true
+
+				continue
+			}
+
+			if err := ths.addTable(name + " " + alias); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// isAlias reports whether name refers to alias of selected field.
+func (ths *schemaScope) isAlias(name identifier) bool {
+	for alias := range ths.aliases {
+		if parts, ok := parseIdentifier(alias); ok && len(parts) == 1 && name.matches(parts[0]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateColumn checks fragment against tables in the scope when it is a simple identifier,
+// optionally qualified by table.
+func (ths *schemaScope) validateColumn(fragment string) error {
+	parts, ok := parseIdentifier(fragment)
+	if !ok || len(parts) > 2 {
+		return nil
+	}
+
+	column := parts[len(parts)-1]
+
+	if len(parts) == 2 {
+		table, ok := ths.table(parts[0])
+		if !ok {
+			return nil
+		}
+
+		if _, ok := table.column(column); !ok {
+			return fmt.Errorf("unknown column %q in table %q", column.name, table.Name)
+		}
+
+		return nil
+	}
+
+	if ths.isAlias(column) || ths.opaque {
+		return nil
+	}
+
+	for _, table := range ths.tables {
+		if _, ok := table.column(column); ok {
+			return nil
+		}
+	}
+
+	if len(ths.tables) == 1 {
+		for _, table := range ths.tables {
+			return fmt.Errorf("unknown column %q in table %q", column.name, table.Name)
+		}
+	}
+
+	return fmt.Errorf("unknown column %q", column.name)
+}
+
+func (ths *schemaScope) validateColumns(fragments []string) error {
+	for _, fragment := range fragments {
+		if err := ths.validateColumn(fragment); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateOnConflict checks conflict target against primary key and unique constraints of table.
+// Targets inferring expression indexes, e.g. (lower(email)), can't be checked and are accepted.
+func validateOnConflict(table Table, onConflict string) error {
+	tokens, err := tokenize(onConflict)
+	if err != nil {
+		return nil
+	}
+
+	index := 0
+	for index+1 < len(tokens) && !(tokens[index].is("ON") && tokens[index+1].is("CONFLICT")) {
+		index++
+	}
+
+	index += 2
+	if index >= len(tokens) {
+		return nil
+	}
+
+	if tokens[index].is("ON") && index+2 < len(tokens) && tokens[index+1].is("CONSTRAINT") {
+		name := unquoteIdentifier(tokens[index+2].text)
+		if name == table.primaryKeyName() {
+			return nil
+		}
+
+		for _, constraint := range table.UniqueConstraints {
+			if constraint.Name == name {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("unknown constraint %q on table %q", name, table.Name)
+	}
+
+	if !tokens[index].is("(") {
+		return nil
+	}
+
+	target := []string{}
+	for _, part := range splitTokens(tokens[index+1:closingParenthesis(tokens, index)], ",") {
+		if len(part) == 0 {
+			return nil
+		}
+
+		// column may be followed by collation or operator class, anything else is expression.
+		for _, current := range part {
+			if current.kind != tokenWord && current.kind != tokenQuotedIdentifier {
+				return nil
+			}
+		}
+
+		target = append(target, unquoteIdentifier(part[0].text))
+	}
+
+	constraints := append([]Constraint{table.PrimaryKey}, table.UniqueConstraints...)
+	for _, constraint := range constraints {
+		if len(constraint.Columns) > 0 && sameColumns(constraint.Columns, target) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf(
+		"on conflict target (%s) does not match any primary key or unique constraint of table %q",
+		strings.Join(target, ", "),
+		table.Name,
+	)
+}
+
+func sameColumns(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	columns := map[string]bool{}
+	for _, column := range a {
+		columns[column] = true
+	}
+
+	for _, column := range b {
+		if !columns[column] {
+			return false
+		}
+	}
+
+	return true
+}
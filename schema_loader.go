@@ -0,0 +1,603 @@
+package squbix
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+var columnConstraintKeywords = map[string]bool{
+	"NOT":            true,
+	"NULL":           true,
+	"DEFAULT":        true,
+	"PRIMARY":        true,
+	"UNIQUE":         true,
+	"REFERENCES":     true,
+	"CHECK":          true,
+	"CONSTRAINT":     true,
+	"COLLATE":        true,
+	"GENERATED":      true,
+	"AUTO_INCREMENT": true,
+	"AUTOINCREMENT":  true,
+	"COMMENT":        true,
+	"ON":             true,
+}
+
+var tableElementKeywords = map[string]bool{
+	"FOREIGN":  true,
+	"CHECK":    true,
+	"EXCLUDE":  true,
+	"KEY":      true,
+	"INDEX":    true,
+	"LIKE":     true,
+	"FULLTEXT": true,
+	"SPATIAL":  true,
+}
+
+// LoadSchemaFromDB loads tables, columns, primary keys and unique constraints of database schema
+// from information_schema, e.g. "public" on postgres or database name on mysql. Dialect sets
+// placeholder style of the queries.
+func LoadSchemaFromDB(ctx context.Context, db *sql.DB, dialect Dialect, databaseSchema string) (*Schema, error) {
+	tables := map[string]*Table{}
+	names := []string{}
+
+	columnsQuery := NewReadQuery("information_schema.columns").
+		WithDialect(dialect).
+		AddSelect("table_name", "column_name", "data_type", "is_nullable").
+		AddWhereExpr(NewExpr("table_schema = ?", databaseSchema)).
+		AddOrderBy("table_name", "ordinal_position")
+
+	err := queryRows(ctx, db, columnsQuery, func(rows *sql.Rows) error {
+		var table, isNullable string
+		var column Column
+
+		if err := rows.Scan(&table, &column.Name, &column.Type, &isNullable); err != nil {
+			return err
+		}
+
+		if _, ok := tables[table]; !ok {
+			tables[table] = &Table{Name: table}
+			names = append(names, table)
+		}
+
+		column.Nullable = strings.EqualFold(isNullable, "YES")
+		tables[table].Columns = append(tables[table].Columns, column)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	constraintsQuery := NewReadQuery("information_schema.table_constraints tc").
+		WithDialect(dialect).
+		AddSelect("tc.table_name", "tc.constraint_name", "tc.constraint_type", "kcu.column_name").
+		AddJoin(`
+			INNER JOIN information_schema.key_column_usage kcu
+			ON kcu.constraint_schema = tc.constraint_schema
+			AND kcu.constraint_name = tc.constraint_name
+			AND kcu.table_name = tc.table_name`,
+		).
+		AddWhereExpr(NewExpr("tc.table_schema = ?", databaseSchema)).
+		AddWhere("tc.constraint_type IN ('PRIMARY KEY', 'UNIQUE')").
+		AddOrderBy("tc.table_name", "tc.constraint_name", "kcu.ordinal_position")
+
+	err = queryRows(ctx, db, constraintsQuery, func(rows *sql.Rows) error {
+		var table, name, kind, column string
+
+		if err := rows.Scan(&table, &name, &kind, &column); err != nil {
+			return err
+		}
+		if _, ok := tables[table]; !ok {
+			return nil
+		}
+
+		if kind == "PRIMARY KEY" {
+			tables[table].PrimaryKey.Name = name
+			tables[table].PrimaryKey.Columns = append(tables[table].PrimaryKey.Columns, column)
+
+			return nil
+		}
+
+		constraints := tables[table].UniqueConstraints
+		if len(constraints) == 0 || constraints[len(constraints)-1].Name != name {
+			constraints = append(constraints, Constraint{Name: name})
+		}
+
+		constraints[len(constraints)-1].Columns = append(constraints[len(constraints)-1].Columns, column)
+		tables[table].UniqueConstraints = constraints
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	schema := NewSchema()
+	for _, name := range names {
+		schema.AddTable(*tables[name])
+	}
+
+	return schema, nil
+}
+
+func queryRows(ctx context.Context, db *sql.DB, builder Builder, scan func(rows *sql.Rows) error) error {
+	query, args, err := builder.BuildQueryWithArgs()
+	if err != nil {
+		return err
+	}
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// LoadSchemaFromDDLFile parses schema from sql dump file, see ParseDDL.
+func LoadSchemaFromDDLFile(path string) (*Schema, error) {
+	ddl, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseDDL(string(ddl))
+}
+
+// ParseDDL parses tables, columns, primary keys and unique constraints from CREATE TABLE,
+// ALTER TABLE ... ADD CONSTRAINT and CREATE UNIQUE INDEX statements, other statements are ignored.
+// Unquoted names are folded to lower case the way postgres catalog stores them, quoted names are
+// kept as written.
+func ParseDDL(ddl string) (*Schema, error) {
+	tokens, err := tokenize(ddl)
+	if err != nil {
+		return nil, err
+	}
+
+	significant := []token{}
+	for _, current := range tokens {
+		if current.kind != tokenComment {
+			significant = append(significant, current)
+		}
+	}
+
+	parser := &ddlParser{
+		ddl:    ddl,
+		tables: map[string]*Table{},
+	}
+
+	for _, statement := range splitTokens(significant, ";") {
+		if err := parser.parseStatement(&tokenCursor{tokens: statement}); err != nil {
+			return nil, err
+		}
+	}
+
+	schema := NewSchema()
+	for _, name := range parser.names {
+		schema.AddTable(*parser.tables[name])
+	}
+
+	return schema, nil
+}
+
+type ddlParser struct {
+	ddl    string
+	tables map[string]*Table
+	names  []string
+}
+
+func (ths *ddlParser) parseStatement(cursor *tokenCursor) error {
+	switch {
+	case cursor.accept("CREATE"):
+		cursor.accept("OR", "REPLACE")
+		cursor.acceptAny("GLOBAL", "LOCAL")
+		cursor.acceptAny("TEMP", "TEMPORARY", "UNLOGGED")
+
+		if cursor.accept("TABLE") {
+			return ths.parseCreateTable(cursor)
+		}
+		if cursor.accept("UNIQUE", "INDEX") {
+			return ths.parseUniqueIndex(cursor)
+		}
+	case cursor.accept("ALTER", "TABLE"):
+		return ths.parseAlterTable(cursor)
+	}
+
+	return nil
+}
+
+func (ths *ddlParser) parseCreateTable(cursor *tokenCursor) error {
+	cursor.accept("IF", "NOT", "EXISTS")
+
+	name, err := cursor.name()
+	if err != nil {
+		return err
+	}
+
+	if !cursor.peek("(") {
+		return nil
+	}
+
+	elements, err := cursor.parenthesized()
+	if err != nil {
+		return err
+	}
+
+	table := &Table{Name: name}
+	for _, element := range splitTokens(elements, ",") {
+		if err := ths.parseTableElement(table, &tokenCursor{tokens: element}); err != nil {
+			return fmt.Errorf("table %q: %s", name, err)
+		}
+	}
+
+	if _, ok := ths.tables[name]; !ok {
+		ths.names = append(ths.names, name)
+	}
+
+	ths.tables[name] = table
+
+	return nil
+}
+
+func (ths *ddlParser) parseTableElement(table *Table, cursor *tokenCursor) error {
+	constraintName := ""
+	if cursor.accept("CONSTRAINT") {
+		name, err := cursor.name()
+		if err != nil {
+			return err
+		}
+
+		constraintName = name
+	}
+
+	if added, err := ths.parseConstraint(table, constraintName, cursor); added || err != nil {
+		return err
+	}
+
+	if len(constraintName) > 0 || cursor.done() {
+		return nil
+	}
+	if cursor.current().kind == tokenWord && tableElementKeywords[strings.ToUpper(cursor.current().text)] {
+		return nil
+	}
+
+	return ths.parseColumn(table, cursor)
+}
+
+// parseConstraint parses PRIMARY KEY (...) or UNIQUE [KEY|INDEX] [name] (...) table constraint.
+func (ths *ddlParser) parseConstraint(table *Table, name string, cursor *tokenCursor) (bool, error) {
+	switch {
+	case cursor.accept("PRIMARY", "KEY"):
+		columns, err := cursor.columnList()
+		if err != nil {
+			return true, err
+		}
+
+		table.PrimaryKey = Constraint{Name: name, Columns: columns}
+		for index := range table.Columns {
+			for _, column := range columns {
+				if table.Columns[index].Name == column {
+					table.Columns[index].Nullable = false
+				}
+			}
+		}
+
+		return true, nil
+	case cursor.accept("UNIQUE"):
+		cursor.acceptAny("KEY", "INDEX")
+
+		if !cursor.peek("(") {
+			indexName, err := cursor.name()
+			if err != nil {
+				return true, err
+			}
+
+			if len(name) == 0 {
+				name = indexName
+			}
+		}
+
+		columns, err := cursor.columnList()
+		if err != nil {
+			return true, err
+		}
+
+		table.UniqueConstraints = append(table.UniqueConstraints, Constraint{Name: name, Columns: columns})
+
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func (ths *ddlParser) parseColumn(table *Table, cursor *tokenCursor) error {
+	column := Column{
+		Name:     identifierName(cursor.next()),
+		Nullable: true,
+	}
+
+	typeStart, typeEnd := -1, -1
+	for depth := 0; !cursor.done(); cursor.next() {
+		current := cursor.current()
+
+		switch {
+		case current.is("("):
+			depth++
+		case current.is(")"):
+			depth--
+		case depth == 0 && current.kind == tokenWord && columnConstraintKeywords[strings.ToUpper(current.text)]:
+			depth = -1
+		case depth == 0 && current.is("CHARACTER") && cursor.lookahead(1).is("SET"):
+			depth = -1
+		}
+
+		if depth < 0 {
+			break
+		}
+
+		if typeStart < 0 {
+			typeStart = current.start
+		}
+
+		typeEnd = current.end
+	}
+
+	if typeStart >= 0 {
+		column.Type = whitespaceNormalizer.ReplaceAllString(ths.ddl[typeStart:typeEnd], " ")
+	}
+
+	for !cursor.done() {
+		switch {
+		case cursor.peek("("):
+			// bodies of CHECK, DEFAULT and GENERATED, e.g. CHECK (note IS NOT NULL), aren't constraints
+			// of the column.
+			if _, err := cursor.parenthesized(); err != nil {
+				return err
+			}
+		case cursor.accept("NOT", "NULL"):
+			column.Nullable = false
+		case cursor.accept("PRIMARY", "KEY"):
+			column.Nullable = false
+			table.PrimaryKey = Constraint{Columns: []string{column.Name}}
+		case cursor.accept("UNIQUE"):
+			table.UniqueConstraints = append(table.UniqueConstraints, Constraint{
+				Name:    fmt.Sprintf("%s_%s_key", table.Name, column.Name),
+				Columns: []string{column.Name},
+			})
+		default:
+			cursor.next()
+		}
+	}
+
+	table.Columns = append(table.Columns, column)
+
+	return nil
+}
+
+func (ths *ddlParser) parseAlterTable(cursor *tokenCursor) error {
+	cursor.accept("IF", "EXISTS")
+	cursor.accept("ONLY")
+
+	name, err := cursor.name()
+	if err != nil {
+		return err
+	}
+
+	table, ok := ths.tables[name]
+	if !ok {
+		return nil
+	}
+
+	remaining := cursor.tokens[cursor.position:]
+	for _, action := range splitTokens(remaining, ",") {
+		actionCursor := &tokenCursor{tokens: action}
+		if !actionCursor.accept("ADD") {
+			continue
+		}
+
+		actionCursor.accept("COLUMN")
+		actionCursor.accept("IF", "NOT", "EXISTS")
+
+		if err := ths.parseTableElement(table, actionCursor); err != nil {
+			return fmt.Errorf("table %q: %s", name, err)
+		}
+	}
+
+	return nil
+}
+
+// parseUniqueIndex parses CREATE UNIQUE INDEX on plain columns, partial and expression indexes
+// are ignored since they can't be used as on conflict target by columns alone.
+func (ths *ddlParser) parseUniqueIndex(cursor *tokenCursor) error {
+	cursor.accept("CONCURRENTLY")
+	cursor.accept("IF", "NOT", "EXISTS")
+
+	indexName := ""
+	if !cursor.peek("ON") {
+		name, err := cursor.name()
+		if err != nil {
+			return err
+		}
+
+		indexName = name
+	}
+
+	if !cursor.accept("ON") {
+		return nil
+	}
+
+	cursor.accept("ONLY")
+
+	name, err := cursor.name()
+	if err != nil {
+		return err
+	}
+
+	if cursor.accept("USING") {
+		cursor.next()
+	}
+
+	columns, err := cursor.columnList()
+	if err != nil || !cursor.done() {
+		return nil
+	}
+
+	if table, ok := ths.tables[name]; ok {
+		table.UniqueConstraints = append(table.UniqueConstraints, Constraint{Name: indexName, Columns: columns})
+	}
+
+	return nil
+}
+
+// tokenCursor walks through tokens of a statement.
+type tokenCursor struct {
+	tokens   []token
+	position int
+}
+
+func (ths *tokenCursor) done() bool {
+	return ths.position >= len(ths.tokens)
+}
+
+func (ths *tokenCursor) current() token {
+	return ths.lookahead(0)
+}
+
+func (ths *tokenCursor) lookahead(offset int) token {
+	if ths.position+offset >= len(ths.tokens) {
+		return token{}
+	}
+
+	return ths.tokens[ths.position+offset]
+}
+
+func (ths *tokenCursor) next() token {
+	current := ths.current()
+	ths.position++
+
+	return current
+}
+
+func (ths *tokenCursor) peek(text string) bool {
+	return ths.current().is(text)
+}
+
+// accept advances the cursor when following tokens match all the words.
+func (ths *tokenCursor) accept(words ...string) bool {
+	for offset, word := range words {
+		if !ths.lookahead(offset).is(word) {
+			return false
+		}
+	}
+
+	ths.position += len(words)
+
+	return true
+}
+
+// acceptAny advances the cursor when next token matches one of the words.
+func (ths *tokenCursor) acceptAny(words ...string) bool {
+	for _, word := range words {
+		if ths.accept(word) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// name reads possibly qualified and quoted name and returns its last part unquoted.
+func (ths *tokenCursor) name() (string, error) {
+	if ths.done() {
+		return "", errors.New("expected name")
+	}
+
+	current := ths.next()
+	if current.kind != tokenWord && current.kind != tokenQuotedIdentifier {
+		return "", fmt.Errorf("expected name at offset %d", current.start)
+	}
+
+	name := identifierName(current)
+	for ths.accept(".") {
+		current = ths.next()
+		name = identifierName(current)
+	}
+
+	return name, nil
+}
+
+// parenthesized reads tokens enclosed by parentheses starting at the cursor.
+func (ths *tokenCursor) parenthesized() ([]token, error) {
+	start := ths.current()
+	if !ths.accept("(") {
+		return nil, fmt.Errorf("expected ( at offset %d", start.start)
+	}
+
+	from := ths.position
+	for depth := 1; !ths.done(); ths.position++ {
+		switch {
+		case ths.current().is("("):
+			depth++
+		case ths.current().is(")"):
+			depth--
+		}
+
+		if depth == 0 {
+			inner := ths.tokens[from:ths.position]
+			ths.position++
+
+			return inner, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unbalanced parentheses at offset %d", start.start)
+}
+
+// columnList reads parenthesized list of column names, optionally followed by sort direction.
+func (ths *tokenCursor) columnList() ([]string, error) {
+	inner, err := ths.parenthesized()
+	if err != nil {
+		return nil, err
+	}
+
+	columns := []string{}
+	for _, part := range splitTokens(inner, ",") {
+		if len(part) == 0 || (part[0].kind != tokenWord && part[0].kind != tokenQuotedIdentifier) {
+			return nil, errors.New("expected column name")
+		}
+
+		rest := part[1:]
+		// mysql allows index prefix length after column name, e.g. name(10)
+		if len(rest) >= 3 && rest[0].is("(") && rest[1].kind == tokenNumber && rest[2].is(")") {
+			rest = rest[3:]
+		}
+		if len(rest) > 1 || (len(rest) == 1 && !rest[0].is("ASC") && !rest[0].is("DESC")) {
+			return nil, fmt.Errorf("expected column name at offset %d", part[0].start)
+		}
+
+		columns = append(columns, identifierName(part[0]))
+	}
+
+	return columns, nil
+}
+
+// identifierName returns name identifier token refers to, unquoted name is folded to lower case and
+// quoted name is kept verbatim.
+func identifierName(current token) string {
+	if current.kind == tokenQuotedIdentifier {
+		return unquoteIdentifier(current.text)
+	}
+
+	return strings.ToLower(current.text)
+}
@@ -0,0 +1,268 @@
+package squbix
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// fakeSchemaConnector serves canned information_schema rows, recording queries it receives.
+type fakeSchemaConnector struct {
+	queries []string
+	args    [][]interface{}
+}
+
+func (ths *fakeSchemaConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return &fakeSchemaConn{connector: ths}, nil
+}
+
+func (ths *fakeSchemaConnector) Driver() driver.Driver {
+	return nil
+}
+
+type fakeSchemaConn struct {
+	connector *fakeSchemaConnector
+}
+
+func (ths *fakeSchemaConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepare is not supported")
+}
+
+func (ths *fakeSchemaConn) Close() error {
+	return nil
+}
+
+func (ths *fakeSchemaConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+func (ths *fakeSchemaConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	values := []interface{}{}
+	for _, arg := range args {
+		values = append(values, arg.Value)
+	}
+
+	ths.connector.queries = append(ths.connector.queries, query)
+	ths.connector.args = append(ths.connector.args, values)
+
+	if strings.Contains(query, "information_schema.columns") {
+		return &fakeSchemaRows{rows: [][]driver.Value{
+			{"users", "id", "bigint", "NO"},
+			{"users", "email", "text", "YES"},
+			{"orders", "id", "bigint", "NO"},
+		}}, nil
+	}
+
+	return &fakeSchemaRows{rows: [][]driver.Value{
+		{"orders", "orders_pkey", "PRIMARY KEY", "id"},
+		{"users", "users_email_key", "UNIQUE", "email"},
+		{"users", "users_pkey", "PRIMARY KEY", "id"},
+	}}, nil
+}
+
+type fakeSchemaRows struct {
+	rows [][]driver.Value
+}
+
+func (ths *fakeSchemaRows) Columns() []string {
+	return []string{"a", "b", "c", "d"}
+}
+
+func (ths *fakeSchemaRows) Close() error {
+	return nil
+}
+
+func (ths *fakeSchemaRows) Next(dest []driver.Value) error {
+	if len(ths.rows) == 0 {
+		return io.EOF
+	}
+
+	copy(dest, ths.rows[0])
+	ths.rows = ths.rows[1:]
+
+	return nil
+}
+
+func TestLoadSchemaFromDB(t *testing.T) {
+	Convey("Given database serving information_schema", t, func() {
+		connector := &fakeSchemaConnector{}
+		db := sql.OpenDB(connector)
+		defer db.Close()
+
+		schema, err := LoadSchemaFromDB(context.Background(), db, Postgres, "public")
+
+		Convey("It should filter tables of the schema in the queries", func() {
+			So(err, ShouldBeNil)
+			So(connector.queries, ShouldResemble, []string{
+				"SELECT table_name, column_name, data_type, is_nullable FROM information_schema.columns " +
+					"WHERE table_schema = $1 ORDER BY table_name, ordinal_position",
+				"SELECT tc.table_name, tc.constraint_name, tc.constraint_type, kcu.column_name FROM information_schema.table_constraints tc " +
+					"INNER JOIN information_schema.key_column_usage kcu ON kcu.constraint_schema = tc.constraint_schema " +
+					"AND kcu.constraint_name = tc.constraint_name AND kcu.table_name = tc.table_name " +
+					"WHERE tc.table_schema = $1 AND tc.constraint_type IN ('PRIMARY KEY', 'UNIQUE') " +
+					"ORDER BY tc.table_name, tc.constraint_name, kcu.ordinal_position",
+			})
+			So(connector.args, ShouldResemble, [][]interface{}{{"public"}, {"public"}})
+		})
+
+		Convey("It should load columns and constraints of the tables", func() {
+			users, ok := schema.Table("users")
+			So(ok, ShouldBeTrue)
+			So(users, ShouldResemble, Table{
+				Name: "users",
+				Columns: []Column{
+					{Name: "id", Type: "bigint"},
+					{Name: "email", Type: "text", Nullable: true},
+				},
+				PrimaryKey:        Constraint{Name: "users_pkey", Columns: []string{"id"}},
+				UniqueConstraints: []Constraint{{Name: "users_email_key", Columns: []string{"email"}}},
+			})
+
+			orders, ok := schema.Table("orders")
+			So(ok, ShouldBeTrue)
+			So(orders.PrimaryKey, ShouldResemble, Constraint{Name: "orders_pkey", Columns: []string{"id"}})
+		})
+	})
+}
+
+func TestParseDDL(t *testing.T) {
+	Convey("Given postgres dump file", t, func() {
+		schema, err := LoadSchemaFromDDLFile("testdata/schema.sql")
+
+		Convey("It should load every table sorted by name", func() {
+			So(err, ShouldBeNil)
+
+			names := []string{}
+			for _, table := range schema.Tables() {
+				names = append(names, table.Name)
+			}
+
+			So(names, ShouldResemble, []string{"orders", "users"})
+		})
+
+		Convey("It should load columns with their types and nullability", func() {
+			users, ok := schema.Table("public.users")
+
+			So(ok, ShouldBeTrue)
+			So(users.Columns, ShouldResemble, []Column{
+				{Name: "id", Type: "bigint", Nullable: false},
+				{Name: "email", Type: "character varying(255)", Nullable: false},
+				{Name: "display name", Type: "text", Nullable: true},
+				{Name: "balance", Type: "numeric(12,2)", Nullable: false},
+				{Name: "created_at", Type: "timestamp with time zone", Nullable: false},
+				{Name: "deleted_at", Type: "timestamp with time zone", Nullable: true},
+				{Name: "nickname", Type: "text", Nullable: true},
+			})
+		})
+
+		Convey("It should load primary keys and unique constraints", func() {
+			users, _ := schema.Table("users")
+			orders, _ := schema.Table("orders")
+
+			So(users.PrimaryKey, ShouldResemble, Constraint{Name: "users_pkey", Columns: []string{"id"}})
+			So(users.UniqueConstraints, ShouldResemble, []Constraint{
+				{Name: "users_email_key", Columns: []string{"email"}},
+			})
+			So(orders.PrimaryKey, ShouldResemble, Constraint{Columns: []string{"id"}})
+			So(orders.UniqueConstraints, ShouldResemble, []Constraint{
+				{Name: "orders_code_key", Columns: []string{"code"}},
+				{Name: "orders_user_code_key", Columns: []string{"user_id", "code"}},
+			})
+		})
+	})
+
+	Convey("Given mysql dump", t, func() {
+		schema, err := ParseDDL("" +
+			"CREATE TABLE `products` (\n" +
+			"  `id` int unsigned NOT NULL AUTO_INCREMENT,\n" +
+			"  `sku` varchar(64) CHARACTER SET utf8mb4 NOT NULL,\n" +
+			"  `name` varchar(255) DEFAULT NULL,\n" +
+			"  PRIMARY KEY (`id`),\n" +
+			"  UNIQUE KEY `products_sku` (`sku`),\n" +
+			"  KEY `products_name` (`name`(10))\n" +
+			") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;\n",
+		)
+
+		Convey("It should load the table", func() {
+			So(err, ShouldBeNil)
+
+			products, ok := schema.Table("products")
+
+			So(ok, ShouldBeTrue)
+			So(products.Columns, ShouldResemble, []Column{
+				{Name: "id", Type: "int unsigned", Nullable: false},
+				{Name: "sku", Type: "varchar(64)", Nullable: false},
+				{Name: "name", Type: "varchar(255)", Nullable: true},
+			})
+			So(products.PrimaryKey, ShouldResemble, Constraint{Columns: []string{"id"}})
+			So(products.UniqueConstraints, ShouldResemble, []Constraint{
+				{Name: "products_sku", Columns: []string{"sku"}},
+			})
+		})
+	})
+
+	Convey("Given DDL with check constraints and names in mixed case", t, func() {
+		schema, err := ParseDDL(`
+			CREATE TABLE Public.Notes (
+				ID bigint NOT NULL CHECK (ID > 0),
+				"Title" text CHECK (length("Title") > 0 AND "Title" IS NOT NULL) NOT NULL,
+				Note text CHECK (Note IS NOT NULL OR ID > 0),
+				Body text DEFAULT (CASE WHEN true THEN 'NOT NULL' END),
+				CONSTRAINT Notes_PKey PRIMARY KEY (ID),
+				CONSTRAINT "Notes_Title_Key" UNIQUE ("Title")
+			);
+		`)
+
+		Convey("It should ignore parenthesized bodies and fold unquoted names only", func() {
+			So(err, ShouldBeNil)
+
+			notes, ok := schema.Table("notes")
+
+			So(ok, ShouldBeTrue)
+			So(notes, ShouldResemble, Table{
+				Name: "notes",
+				Columns: []Column{
+					{Name: "id", Type: "bigint", Nullable: false},
+					{Name: "Title", Type: "text", Nullable: false},
+					{Name: "note", Type: "text", Nullable: true},
+					{Name: "body", Type: "text", Nullable: true},
+				},
+				PrimaryKey: Constraint{Name: "notes_pkey", Columns: []string{"id"}},
+				UniqueConstraints: []Constraint{
+					{Name: "Notes_Title_Key", Columns: []string{"Title"}},
+				},
+			})
+		})
+	})
+
+	Convey("Given loaded schema bound to builder", t, func() {
+		schema, _ := LoadSchemaFromDDLFile("testdata/schema.sql")
+		query, err := NewCreateQuery("orders").
+			WithSchema(schema).
+			AddField("user_id", "code").
+			AddValue("(1, 'A')").
+			AddOnConflict("ON CONFLICT (code, user_id) DO NOTHING").
+			BuildQuery()
+
+		Convey("It should validate query against it", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "INSERT INTO orders (user_id, code) VALUES (1, 'A') ON CONFLICT (code, user_id) DO NOTHING")
+		})
+	})
+
+	Convey("Given malformed DDL", t, func() {
+		schema, err := ParseDDL("CREATE TABLE users (id bigint, name text DEFAULT 'unterminated);")
+
+		Convey("It should returns error", func() {
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "unterminated string literal at offset 49")
+			So(schema, ShouldBeNil)
+		})
+	})
+}
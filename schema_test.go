@@ -0,0 +1,315 @@
+package squbix
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func newTestSchema() *Schema {
+	return NewSchema(
+		Table{
+			Name: "table_a",
+			Columns: []Column{
+				{Name: "id", Type: "bigint"},
+				{Name: "field_a", Type: "text"},
+				{Name: "field_b", Type: "text", Nullable: true},
+			},
+			PrimaryKey: Constraint{Columns: []string{"id"}},
+			UniqueConstraints: []Constraint{
+				{Name: "table_a_field_a_key", Columns: []string{"field_a"}},
+			},
+		},
+		Table{
+			Name: "table_b",
+			Columns: []Column{
+				{Name: "id", Type: "bigint"},
+				{Name: "table_a_id", Type: "bigint"},
+				{Name: "field_c", Type: "integer"},
+			},
+			PrimaryKey: Constraint{Name: "table_b_primary", Columns: []string{"id"}},
+		},
+	)
+}
+
+func TestSchema(t *testing.T) {
+	schema := newTestSchema()
+
+	Convey("Given read query on unknown table", t, func() {
+		query, err := NewReadQuery("table_x").
+			WithSchema(schema).
+			AddSelect("field_a").
+			BuildQuery()
+
+		Convey("It should returns error", func() {
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, `unknown table "table_x"`)
+			So(query, ShouldEqual, "")
+		})
+	})
+
+	Convey("Given read query selecting unknown column", t, func() {
+		query, err := NewReadQuery("table_a").
+			WithSchema(schema).
+			AddSelect("id", "feild_a").
+			BuildQuery()
+
+		Convey("It should returns error", func() {
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, `unknown column "feild_a" in table "table_a"`)
+			So(query, ShouldEqual, "")
+		})
+	})
+
+	Convey("Given read query joining unknown table", t, func() {
+		query, err := NewReadQuery("table_a").
+			WithSchema(schema).
+			AddSelect("id").
+			AddJoin("LEFT JOIN table_x ON table_x.id = table_a.id").
+			BuildQuery()
+
+		Convey("It should returns error", func() {
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, `unknown table "table_x"`)
+			So(query, ShouldEqual, "")
+		})
+	})
+
+	Convey("Given read query with qualified unknown column of joined table", t, func() {
+		query, err := NewReadQuery("table_a a").
+			WithSchema(schema).
+			AddSelect("a.field_a", "b.field_d").
+			AddJoin("LEFT JOIN table_b b ON b.table_a_id = a.id").
+			BuildQuery()
+
+		Convey("It should returns error", func() {
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, `unknown column "field_d" in table "table_b"`)
+			So(query, ShouldEqual, "")
+		})
+	})
+
+	Convey("Given read query with unknown group by column", t, func() {
+		query, err := NewReadQuery("table_a").
+			WithSchema(schema).
+			AddSelect("field_a").
+			AddGroupBy("field_x").
+			BuildQuery()
+
+		Convey("It should returns error", func() {
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, `unknown column "field_x" in table "table_a"`)
+			So(query, ShouldEqual, "")
+		})
+	})
+
+	Convey("Given valid read query with expressions, aliases and order directions", t, func() {
+		query, err := NewReadQuery("table_a").
+			WithSchema(schema).
+			AddSelect("table_a.field_a", "field_b AS b", "COUNT(*) AS total").
+			AddJoin("INNER JOIN table_b ON table_b.table_a_id = table_a.id").
+			AddGroupBy("table_a.field_a", "field_b").
+			AddOrderBy("total DESC", "field_c ASC NULLS LAST").
+			BuildQuery()
+
+		Convey("It should returns generated query", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT table_a.field_a, field_b AS b, COUNT(*) AS total FROM table_a INNER JOIN table_b ON table_b.table_a_id = table_a.id GROUP BY table_a.field_a, field_b ORDER BY total DESC, field_c ASC NULLS LAST")
+		})
+	})
+
+	Convey("Given read query from subquery", t, func() {
+		query, err := NewReadQuery("(SELECT 1 AS one) AS sub").
+			WithSchema(schema).
+			AddSelect("one").
+			BuildQuery()
+
+		Convey("It should skip columns it can't resolve", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT one FROM (SELECT 1 AS one) AS sub")
+		})
+	})
+
+	Convey("Given create query with unknown field", t, func() {
+		query, err := NewCreateQuery("table_a").
+			WithSchema(schema).
+			AddField("id", "nmae").
+			AddValue("(1, 'a')").
+			BuildQuery()
+
+		Convey("It should returns error", func() {
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, `unknown column "nmae" in table "table_a"`)
+			So(query, ShouldEqual, "")
+		})
+	})
+
+	Convey("Given create query with on conflict target matching no unique constraint", t, func() {
+		query, err := NewCreateQuery("table_a").
+			WithSchema(schema).
+			AddField("id", "field_b").
+			AddValue("(1, 'a')").
+			AddOnConflict("ON CONFLICT (field_b) DO NOTHING").
+			BuildQuery()
+
+		Convey("It should returns error", func() {
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, `on conflict target (field_b) does not match any primary key or unique constraint of table "table_a"`)
+			So(query, ShouldEqual, "")
+		})
+	})
+
+	Convey("Given create query with on conflict on unknown constraint", t, func() {
+		query, err := NewCreateQuery("table_b").
+			WithSchema(schema).
+			AddField("id", "table_a_id").
+			AddValue("(1, 1)").
+			AddOnConflict("ON CONFLICT ON CONSTRAINT table_b_pkey DO NOTHING").
+			BuildQuery()
+
+		Convey("It should returns error", func() {
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, `unknown constraint "table_b_pkey" on table "table_b"`)
+			So(query, ShouldEqual, "")
+		})
+	})
+
+	Convey("Given create query with valid on conflict targets", t, func() {
+		_, errColumns := NewCreateQuery("table_a").
+			WithSchema(schema).
+			AddField("id", "field_a").
+			AddValue("(1, 'a')").
+			AddOnConflict("ON CONFLICT (field_a) DO UPDATE SET id = EXCLUDED.id").
+			BuildQuery()
+		_, errDefaultPrimaryKey := NewCreateQuery("table_a").
+			WithSchema(schema).
+			AddField("id", "field_a").
+			AddValue("(1, 'a')").
+			AddOnConflict("ON CONFLICT ON CONSTRAINT table_a_pkey DO NOTHING").
+			BuildQuery()
+		_, errNamedPrimaryKey := NewCreateQuery("table_b").
+			WithSchema(schema).
+			AddField("id", "table_a_id").
+			AddValue("(1, 1)").
+			AddOnConflict("ON CONFLICT ON CONSTRAINT table_b_primary DO NOTHING").
+			BuildQuery()
+
+		_, errExpression := NewCreateQuery("table_a").
+			WithSchema(schema).
+			AddField("id", "field_b").
+			AddValue("(1, 'a')").
+			AddOnConflict("ON CONFLICT ((lower(field_b))) DO NOTHING").
+			BuildQuery()
+		_, errCollation := NewCreateQuery("table_a").
+			WithSchema(schema).
+			AddField("id", "field_a").
+			AddValue("(1, 'a')").
+			AddOnConflict(`ON CONFLICT ("field_a" COLLATE "C") WHERE field_b IS NULL DO NOTHING`).
+			BuildQuery()
+
+		Convey("It should returns no error", func() {
+			So(errColumns, ShouldBeNil)
+			So(errDefaultPrimaryKey, ShouldBeNil)
+			So(errNamedPrimaryKey, ShouldBeNil)
+			So(errExpression, ShouldBeNil)
+			So(errCollation, ShouldBeNil)
+		})
+	})
+
+	Convey("Given read query selecting from common table expressions", t, func() {
+		query, err := NewReadQuery("recent r").
+			WithSchema(schema).
+			AddCTE("recent AS (SELECT id FROM table_a)", `RECURSIVE "tree"(id) AS (SELECT 1), ranked AS (SELECT 1)`).
+			AddJoin("JOIN tree ON tree.id = r.id", "JOIN table_a a ON a.id = r.id").
+			AddSelect("r.id", "a.field_a", "total").
+			BuildQuery()
+		_, unknownErr := NewReadQuery("recent").
+			WithSchema(schema).
+			AddCTE("recent AS (SELECT id FROM table_a)").
+			AddJoin("JOIN table_z z ON z.id = recent.id").
+			AddSelect("id").
+			BuildQuery()
+
+		Convey("It should accept their names and keep validating other tables", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldNotBeEmpty)
+			So(unknownErr, ShouldBeError, `unknown table "table_z"`)
+		})
+	})
+
+	Convey("Given queries referring to tables and columns in other case or quoted", t, func() {
+		read, readErr := NewReadQuery("TABLE_A A").
+			WithSchema(schema).
+			AddJoin(`LEFT JOIN public."table_b" "B" ON "B".table_a_id = a.ID`).
+			AddSelect("A.ID", `"field_a"`, `"B".FIELD_C`, `COUNT(*) AS "Total"`).
+			AddOrderBy(`"Total" DESC`).
+			BuildQuery()
+		update, updateErr := NewUpdateQuery("Table_B").
+			WithSchema(schema).
+			AddSetField(`"field_c" = 1`, "FIELD_C = FIELD_C + 1").
+			AddWhere("id = :id").
+			BuildQuery()
+		_, quotedTableErr := NewReadQuery(`"TABLE_A"`).
+			WithSchema(schema).
+			AddSelect("id").
+			BuildQuery()
+		_, quotedColumnErr := NewReadQuery("table_a").
+			WithSchema(schema).
+			AddSelect(`"ID"`).
+			BuildQuery()
+		_, quotedSetErr := NewUpdateQuery("table_b").
+			WithSchema(schema).
+			AddSetField(`"Field_C" = 1`).
+			AddWhere("id = :id").
+			BuildQuery()
+
+		Convey("It should match unquoted names ignoring case and quoted names exactly", func() {
+			So(readErr, ShouldBeNil)
+			So(read, ShouldNotBeEmpty)
+			So(updateErr, ShouldBeNil)
+			So(update, ShouldNotBeEmpty)
+			So(quotedTableErr, ShouldBeError, `unknown table "\"TABLE_A\""`)
+			So(quotedColumnErr, ShouldBeError, `unknown column "ID" in table "table_a"`)
+			So(quotedSetErr, ShouldBeError, `unknown column "Field_C" in table "table_b"`)
+		})
+	})
+
+	Convey("Given update query setting unknown field", t, func() {
+		query, err := NewUpdateQuery("table_b").
+			WithSchema(schema).
+			AddSetField("field_c = field_c + 1", "field_z = 'A'").
+			AddWhere("id = :id").
+			BuildQuery()
+
+		Convey("It should returns error", func() {
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, `unknown column "field_z" in table "table_b"`)
+			So(query, ShouldEqual, "")
+		})
+	})
+
+	Convey("Given delete query on unknown table", t, func() {
+		query, err := NewDeleteQuery("table_x").
+			WithSchema(schema).
+			AddWhere("id = :id").
+			BuildQuery()
+
+		Convey("It should returns error", func() {
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, `unknown table "table_x"`)
+			So(query, ShouldEqual, "")
+		})
+	})
+
+	Convey("Given schema encoded as JSON", t, func() {
+		data, err := json.Marshal(schema)
+		decoded := NewSchema()
+
+		Convey("It should decode into the same schema", func() {
+			So(err, ShouldBeNil)
+			So(json.Unmarshal(data, decoded), ShouldBeNil)
+			So(decoded.Tables(), ShouldResemble, schema.Tables())
+		})
+	})
+}
@@ -0,0 +1,115 @@
+package squbix
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// TextSearch is full-text search over columns in the dialect. Postgres and standard dialects match
+// to_tsvector of the columns against plainto_tsquery of the text, mysql uses MATCH ... AGAINST in
+// natural language mode and needs FULLTEXT index over exactly the columns, sqlite matches FTS5
+// table given as the only column. The search text is always bound as argument. Building query
+// using the search fails when it has no column or the builder dialect, unless standard, differs.
+type TextSearch struct {
+	dialect  Dialect
+	columns  []string
+	language string
+}
+
+// NewTextSearch creates full-text search over columns in the dialect.
+func NewTextSearch(dialect Dialect, columns ...string) *TextSearch {
+	return &TextSearch{
+		dialect: dialect,
+		columns: columns,
+	}
+}
+
+// Language sets postgres text search configuration, e.g. english, so expression index built with
+// the same configuration can be used. Server default configuration is used when it is not set.
+func (ths *TextSearch) Language(config string) *TextSearch {
+	ths.language = config
+
+	return ths
+}
+
+// Match returns condition matching rows containing the text.
+func (ths *TextSearch) Match(text string) Expr {
+	switch ths.dialect {
+	case MySQL:
+		return ths.checked(NewExpr(ths.against(), text))
+	case SQLite:
+		return ths.checked(NewExpr(fmt.Sprintf("%s MATCH ?", ths.table()), text))
+	}
+
+	return ths.checked(NewExpr(fmt.Sprintf("%s @@ %s", ths.document(), ths.query()), text))
+}
+
+// Rank returns relevance of row to the text, higher is more relevant, to select or order by. Sqlite
+// ranks rows by the text they were matched with, so it binds no argument.
+func (ths *TextSearch) Rank(text string) Expr {
+	switch ths.dialect {
+	case MySQL:
+		return ths.checked(NewExpr(ths.against(), text))
+	case SQLite:
+		return ths.checked(rawExpr(fmt.Sprintf("-bm25(%s)", ths.table())))
+	}
+
+	return ths.checked(NewExpr(fmt.Sprintf("ts_rank(%s, %s)", ths.document(), ths.query()), text))
+}
+
+// checked returns search expression failing to build without column or in other dialect, standard
+// search is written for postgres.
+func (ths *TextSearch) checked(expr Expr) Expr {
+	if len(ths.columns) == 0 {
+		expr.err = errors.New("full-text search has no column, add it using NewTextSearch function")
+	}
+
+	dialect := ths.dialect
+	if dialect == Standard {
+		dialect = Postgres
+	}
+
+	return expr.requiring(fmt.Sprintf("full-text search of %s dialect", dialect), dialect)
+}
+
+func (ths *TextSearch) document() string {
+	text := ""
+
+	if len(ths.columns) == 1 {
+		text = ths.columns[0]
+	} else {
+		columns := make([]string, 0, len(ths.columns))
+		for _, column := range ths.columns {
+			columns = append(columns, fmt.Sprintf("COALESCE(%s, '')", column))
+		}
+
+		text = strings.Join(columns, " || ' ' || ")
+	}
+
+	return fmt.Sprintf("to_tsvector(%s%s)", ths.config(), text)
+}
+
+func (ths *TextSearch) query() string {
+	return fmt.Sprintf("plainto_tsquery(%s?)", ths.config())
+}
+
+func (ths *TextSearch) config() string {
+	if len(ths.language) == 0 {
+		return ""
+	}
+
+	return stringLiteral(ths.language, Postgres) + ", "
+}
+
+func (ths *TextSearch) against() string {
+	return fmt.Sprintf("MATCH (%s) AGAINST (? IN NATURAL LANGUAGE MODE)", strings.Join(ths.columns, ", "))
+}
+
+func (ths *TextSearch) table() string {
+	if len(ths.columns) == 0 {
+		return ""
+	}
+
+	return ths.columns[0]
+}
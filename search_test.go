@@ -0,0 +1,95 @@
+package squbix
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTextSearch(t *testing.T) {
+	Convey("Given postgres full-text search over many columns", t, func() {
+		search := NewTextSearch(Postgres, "title", "body").Language("english")
+
+		query, args, err := NewReadQuery("articles").
+			WithDialect(Postgres).
+			AddSelect("id").
+			AddSelectExpr(search.Rank("go tips").As("rank")).
+			AddWhereExpr(search.Match("go tips"), NewExpr("published = ?", true)).
+			AddOrderBy("rank DESC").
+			BuildQueryWithArgs()
+
+		Convey("It should match and rank tsvector of the columns against bound text", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT id, ts_rank(to_tsvector('english', COALESCE(title, '') || ' ' || COALESCE(body, '')), plainto_tsquery('english', $1)) AS rank "+
+				"FROM articles WHERE to_tsvector('english', COALESCE(title, '') || ' ' || COALESCE(body, '')) @@ plainto_tsquery('english', $2) AND published = $3 ORDER BY rank DESC")
+			So(args, ShouldResemble, []interface{}{"go tips", "go tips", true})
+		})
+	})
+
+	Convey("Given full-text search over single column in default configuration", t, func() {
+		search := NewTextSearch(Standard, "title")
+
+		query, args, err := NewReadQuery("articles").
+			AddSelect("id").
+			AddWhereExpr(search.Match("it's")).
+			AddOrderByExpr(search.Rank("it's")).
+			BuildQueryWithArgs()
+
+		Convey("It should use the column and server configuration", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT id FROM articles WHERE to_tsvector(title) @@ plainto_tsquery(?) ORDER BY ts_rank(to_tsvector(title), plainto_tsquery(?))")
+			So(args, ShouldResemble, []interface{}{"it's", "it's"})
+		})
+	})
+
+	Convey("Given mysql and sqlite full-text search", t, func() {
+		mysql := NewTextSearch(MySQL, "title", "body")
+		sqlite := NewTextSearch(SQLite, "articles_fts")
+
+		mysqlQuery, mysqlArgs, mysqlErr := NewReadQuery("articles").
+			WithDialect(MySQL).
+			AddSelect("id").
+			AddSelectExpr(mysql.Rank("go").As("score")).
+			AddWhereExpr(mysql.Match("go")).
+			BuildQueryWithArgs()
+		sqliteQuery, sqliteArgs, sqliteErr := NewReadQuery("articles_fts").
+			WithDialect(SQLite).
+			AddSelect("rowid").
+			AddWhereExpr(sqlite.Match("go")).
+			AddOrderByExpr(sqlite.Rank("go")).
+			BuildQueryWithArgs()
+
+		Convey("It should use MATCH AGAINST and FTS5 MATCH", func() {
+			So(mysqlErr, ShouldBeNil)
+			So(mysqlQuery, ShouldEqual, "SELECT id, MATCH (title, body) AGAINST (? IN NATURAL LANGUAGE MODE) AS score FROM articles WHERE MATCH (title, body) AGAINST (? IN NATURAL LANGUAGE MODE)")
+			So(mysqlArgs, ShouldResemble, []interface{}{"go", "go"})
+			So(sqliteErr, ShouldBeNil)
+			So(sqliteQuery, ShouldEqual, "SELECT rowid FROM articles_fts WHERE articles_fts MATCH ? ORDER BY -bm25(articles_fts)")
+			So(sqliteArgs, ShouldResemble, []interface{}{"go"})
+		})
+	})
+
+	Convey("Given full-text search without column or of other dialect than the builder", t, func() {
+		_, noColumnErr := NewReadQuery("articles").
+			AddSelect("id").
+			AddWhereExpr(NewTextSearch(Postgres).Match("go")).
+			BuildQuery()
+		_, mismatchErr := NewReadQuery("articles").
+			WithDialect(MySQL).
+			AddSelect("id").
+			AddWhereExpr(NewExpr("published = ?", true)).
+			AddOrderByExpr(NewTextSearch(Postgres, "title").Rank("go")).
+			BuildQuery()
+		_, standardErr := NewReadQuery("articles_fts").
+			WithDialect(SQLite).
+			AddSelect("rowid").
+			AddWhereExpr(NewTextSearch(Standard, "title").Match("go")).
+			BuildQuery()
+
+		Convey("It should return the errors", func() {
+			So(noColumnErr, ShouldBeError, "full-text search has no column, add it using NewTextSearch function")
+			So(mismatchErr, ShouldBeError, "full-text search of postgres dialect is not supported by mysql dialect")
+			So(standardErr, ShouldBeError, "full-text search of postgres dialect is not supported by sqlite dialect")
+		})
+	})
+}
@@ -0,0 +1,64 @@
+package squbix
+
+import (
+	"strings"
+)
+
+// StatementKind is kind of statement a builder generates.
+type StatementKind string
+
+const (
+	// SelectStatement is generated by read query builder and queries derived from it.
+	SelectStatement StatementKind = "SELECT"
+	// InsertStatement is generated by create query builder.
+	InsertStatement StatementKind = "INSERT"
+	// UpdateStatement is generated by update query builder.
+	UpdateStatement StatementKind = "UPDATE"
+	// DeleteStatement is generated by delete query builder, soft deletes included.
+	DeleteStatement StatementKind = "DELETE"
+)
+
+// Shape describes statement of builder without its sql, it lets tests match queries by what they
+// do rather than how they are written.
+type Shape struct {
+	Kind StatementKind
+	// Table is the first table of the statement without alias.
+	Table string
+	// Conditions are where fragments added to the builder, with ? placeholders.
+	Conditions []string
+}
+
+// ShapeOf returns shape of statement the builder generates.
+func ShapeOf(builder Builder) Shape {
+	return builder.shape()
+}
+
+// shapeTable returns table of fragment without its alias.
+func shapeTable(fragment string) string {
+	if table, _ := splitTableAlias(fragment); len(table) > 0 {
+		return table
+	}
+
+	return strings.TrimSpace(fragment)
+}
+
+// shapeConditions returns sql of where expressions as written, escaped question marks included.
+func shapeConditions(exprs []Expr) []string {
+	conditions := make([]string, 0, len(exprs))
+
+	for _, expr := range exprs {
+		condition := &strings.Builder{}
+
+		scanPlaceholders(expr.SQL, func(kind placeholderSegment, text string) {
+			if kind == segmentText {
+				condition.WriteString(text)
+			} else {
+				condition.WriteRune(placeholder)
+			}
+		})
+
+		conditions = append(conditions, condition.String())
+	}
+
+	return conditions
+}
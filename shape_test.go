@@ -0,0 +1,44 @@
+package squbix
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestShapeOf(t *testing.T) {
+	Convey("Given builders of every operation", t, func() {
+		read := NewReadQuery("orders AS o").
+			AddSelect("o.id").
+			AddWhere("o.data ? 'key'").
+			AddWhereExpr(NewExpr("o.status = ?", "paid"))
+
+		Convey("It should describe their statements", func() {
+			So(ShapeOf(read), ShouldResemble, Shape{
+				Kind:       SelectStatement,
+				Table:      "orders",
+				Conditions: []string{"o.data ? 'key'", "o.status = ?"},
+			})
+			So(ShapeOf(read.ExistsQuery()), ShouldResemble, Shape{
+				Kind:       SelectStatement,
+				Table:      "orders",
+				Conditions: []string{"o.data ? 'key'", "o.status = ?"},
+			})
+			So(ShapeOf(NewCreateQuery("orders").AddField("id").AddValue("(1)")), ShouldResemble, Shape{
+				Kind:       InsertStatement,
+				Table:      "orders",
+				Conditions: []string{},
+			})
+			So(ShapeOf(NewUpdateQuery("orders").AddWhere("id = 1")), ShouldResemble, Shape{
+				Kind:       UpdateStatement,
+				Table:      "orders",
+				Conditions: []string{"id = 1"},
+			})
+			So(ShapeOf(Explain(NewDeleteQuery("orders"), ExplainOptions{})), ShouldResemble, Shape{
+				Kind:       DeleteStatement,
+				Table:      "orders",
+				Conditions: []string{},
+			})
+		})
+	})
+}
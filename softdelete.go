@@ -0,0 +1,309 @@
+package squbix
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+const defaultSoftDeleteValue = "now()"
+
+var (
+	joinTypeKeywords  = map[string]bool{"LEFT": true, "RIGHT": true, "FULL": true, "INNER": true, "CROSS": true, "OUTER": true, "NATURAL": true}
+	joinTableKeywords = map[string]bool{"ON": true, "USING": true, "LATERAL": true, "JOIN": true, "WHERE": true, "LEFT": true, "RIGHT": true, "FULL": true, "INNER": true, "CROSS": true, "OUTER": true, "NATURAL": true}
+)
+
+// SoftDelete describes how rows of a table are marked as deleted instead of being removed.
+type SoftDelete struct {
+	// Column marks deleted rows, rows having NULL on this column are considered not deleted.
+	Column string
+	// Value is expression assigned to Column on delete, defaults to now().
+	Value string
+}
+
+type softDeleteScope int
+
+const (
+	excludeDeleted softDeleteScope = iota
+	includeDeleted
+	onlyDeleted
+)
+
+var softDeleteRegistry = struct {
+	sync.RWMutex
+	tables map[string]SoftDelete
+}{
+	tables: map[string]SoftDelete{},
+}
+
+// RegisterSoftDelete enables soft delete for table. Delete queries on the table will be
+// generated as update queries and read queries will exclude soft deleted rows. Table may be
+// qualified with database schema, e.g. public.users, unqualified table matches table of any schema.
+// Registrations are shared by every builder of the process, register tables at initialization,
+// e.g. in init function, before any query is built.
+func RegisterSoftDelete(table string, softDelete SoftDelete) {
+	if len(softDelete.Value) == 0 {
+		softDelete.Value = defaultSoftDeleteValue
+	}
+
+	softDeleteRegistry.Lock()
+	defer softDeleteRegistry.Unlock()
+
+	softDeleteRegistry.tables[table] = softDelete
+}
+
+// UnregisterSoftDelete disables soft delete for table.
+func UnregisterSoftDelete(table string) {
+	softDeleteRegistry.Lock()
+	defer softDeleteRegistry.Unlock()
+
+	delete(softDeleteRegistry.tables, table)
+}
+
+// lookupSoftDelete returns soft delete of table registered by name referring to the same table, e.g.
+// users registration applies to public.users and USERS.
+func lookupSoftDelete(table string) (SoftDelete, bool) {
+	softDeleteRegistry.RLock()
+	defer softDeleteRegistry.RUnlock()
+
+	if softDelete, ok := softDeleteRegistry.tables[table]; ok {
+		return softDelete, true
+	}
+
+	reference, ok := parseIdentifier(table)
+	if !ok {
+		return SoftDelete{}, false
+	}
+
+	for name, softDelete := range softDeleteRegistry.tables {
+		if registered, ok := parseIdentifier(name); ok && sameTable(reference, registered) {
+			return softDelete, true
+		}
+	}
+
+	return SoftDelete{}, false
+}
+
+// splitTableAlias splits table fragment like "users", "users u" or "users AS u" into table name
+// and the name used to refer to it. It returns empty strings for anything else, e.g. subqueries.
+func splitTableAlias(fragment string) (string, string) {
+	fields := strings.Fields(fragment)
+
+	switch {
+	case len(fields) == 1:
+		return fields[0], fields[0]
+	case len(fields) == 2:
+		return fields[0], fields[1]
+	case len(fields) == 3 && strings.EqualFold(fields[1], "AS"):
+		return fields[0], fields[2]
+	}
+
+	return "", ""
+}
+
+// softDeleteConditions returns conditions limiting rows of soft deletable tables to the scope.
+func softDeleteConditions(tables []string, scope softDeleteScope) []string {
+	if scope == includeDeleted {
+		return nil
+	}
+
+	conditions := []string{}
+
+	for _, fragment := range tables {
+		table, alias := splitTableAlias(fragment)
+
+		softDelete, ok := lookupSoftDelete(table)
+		if !ok {
+			continue
+		}
+
+		operator := "IS NULL"
+		if scope == onlyDeleted {
+			operator = "IS NOT NULL"
+		}
+
+		conditions = append(conditions, fmt.Sprintf("%s.%s %s", alias, softDelete.Column, operator))
+	}
+
+	return conditions
+}
+
+// softDeleteJoins adds conditions limiting rows of soft deletable tables joined by join fragments
+// to rows which are not deleted into ON condition of their join, so outer joins keep their meaning.
+// Conditions of inner joins without ON condition are returned to be added to where clause.
+func softDeleteJoins(fragments []Expr, scope softDeleteScope) ([]Expr, []Expr, error) {
+	if scope == includeDeleted {
+		return fragments, nil, nil
+	}
+
+	joins := make([]Expr, 0, len(fragments))
+	conditions := []Expr{}
+
+	for _, fragment := range fragments {
+		join, joinConditions, err := softDeleteJoin(fragment)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		joins = append(joins, join)
+		conditions = append(conditions, joinConditions...)
+	}
+
+	return joins, conditions, nil
+}
+
+// softDeleteJoin adds soft delete conditions to every join of join fragment. Fragments which can't be
+// tokenized are returned as they are.
+func softDeleteJoin(fragment Expr) (Expr, []Expr, error) {
+	tokens, err := tokenize(fragment.SQL)
+	if err != nil {
+		return fragment, nil, nil
+	}
+
+	joins := []int{}
+	depth := 0
+
+	for index, current := range tokens {
+		switch {
+		case current.is("("):
+			depth++
+		case current.is(")"):
+			depth--
+		case depth == 0 && current.is("JOIN"):
+			joins = append(joins, index)
+		}
+	}
+
+	sql := fragment.SQL
+	conditions := []Expr{}
+
+	// joins are rewritten from the last one, so offsets of the ones before stay valid.
+	for position := len(joins) - 1; position >= 0; position-- {
+		end := len(tokens)
+		if position+1 < len(joins) {
+			end = joinTypeStart(tokens, joins[position+1])
+		}
+
+		table, alias, next := joinedTable(tokens, joins[position], end)
+
+		softDelete, ok := lookupSoftDelete(table)
+		if !ok {
+			continue
+		}
+
+		condition := fmt.Sprintf("%s.%s IS NULL", alias, softDelete.Column)
+		on := -1
+		depth := 0
+
+		for index := next; index < end && on < 0; index++ {
+			switch {
+			case tokens[index].is("("):
+				depth++
+			case tokens[index].is(")"):
+				depth--
+			case depth == 0 && tokens[index].is("ON"):
+				on = index
+			}
+		}
+
+		if on < 0 || on+1 == end {
+			if isOuterJoin(tokens[joinTypeStart(tokens, joins[position]):joins[position]]) {
+				return Expr{}, nil, fmt.Errorf("can't exclude soft deleted rows of table %q joined without ON condition", table)
+			}
+
+			conditions = append([]Expr{rawExpr(condition)}, conditions...)
+
+			continue
+		}
+
+		last := end - 1
+		for last > on && tokens[last].kind == tokenComment {
+			last--
+		}
+
+		sql = sql[:tokens[on+1].start] + "(" + sql[tokens[on+1].start:tokens[last].end] + ") AND " +
+			strings.Replace(condition, "?", "??", -1) + sql[tokens[last].end:]
+	}
+
+	fragment.SQL = sql
+
+	return fragment, conditions, nil
+}
+
+// joinTypeStart returns index of the first join type keyword, e.g. LEFT OUTER, before JOIN keyword
+// at index.
+func joinTypeStart(tokens []token, index int) int {
+	for index > 0 && joinTypeKeywords[strings.ToUpper(tokens[index-1].text)] && tokens[index-1].kind == tokenWord {
+		index--
+	}
+
+	return index
+}
+
+// joinedTable returns name and alias of table joined by JOIN keyword at index together with index of
+// the token following them. Name is empty when subquery is joined.
+func joinedTable(tokens []token, index int, end int) (string, string, int) {
+	next := index + 1
+	if next >= end || !isIdentifierToken(tokens[next]) || joinTableKeywords[strings.ToUpper(tokens[next].text)] {
+		return "", "", next
+	}
+
+	name := tokens[next].text
+	for next+2 < end && tokens[next+1].is(".") && isIdentifierToken(tokens[next+2]) {
+		name += "." + tokens[next+2].text
+		next += 2
+	}
+
+	next++
+	alias := name
+
+	if next < end && tokens[next].is("AS") {
+		next++
+	}
+
+	if next < end && isIdentifierToken(tokens[next]) && !joinTableKeywords[strings.ToUpper(tokens[next].text)] {
+		alias = tokens[next].text
+		next++
+	}
+
+	return name, alias, next
+}
+
+func isIdentifierToken(current token) bool {
+	return current.kind == tokenWord || current.kind == tokenQuotedIdentifier
+}
+
+func isOuterJoin(keywords []token) bool {
+	for _, keyword := range keywords {
+		if keyword.is("LEFT") || keyword.is("RIGHT") || keyword.is("FULL") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// guardConditions appends conditions generated by the library to where fragments, wrapping the
+// fragments, and conditions having top-level OR, in parentheses so OR can never bypass the
+// conditions, whether automatic parenthesization is enabled or not.
+func guardConditions(fragments []Expr, conditions []Expr, dialect Dialect) []Expr {
+	if len(conditions) == 0 {
+		return fragments
+	}
+
+	guarded := []Expr{}
+
+	if len(fragments) > 0 {
+		guarded = append(guarded, formatExpr("(%s)", joinExprs(fragments, " AND ")))
+	}
+
+	for _, condition := range conditions {
+		if len(conditions)+len(guarded) > 1 && hasTopLevelOr(condition.SQL, dialect) {
+			condition = formatExpr("(%s)", condition)
+		}
+
+		guarded = append(guarded, condition)
+	}
+
+	return guarded
+}
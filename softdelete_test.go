@@ -0,0 +1,183 @@
+package squbix
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSoftDelete(t *testing.T) {
+	RegisterSoftDelete("table_a", SoftDelete{Column: "deleted_at"})
+	RegisterSoftDelete("table_b", SoftDelete{Column: "removed_at", Value: "CURRENT_TIMESTAMP"})
+	defer UnregisterSoftDelete("table_a")
+	defer UnregisterSoftDelete("table_b")
+
+	Convey("Given delete query on soft deletable table", t, func() {
+		query, err := NewDeleteQuery("table_a").
+			AddWhere(
+				"table_a.id = :id",
+			).
+			BuildQuery()
+
+		Convey("It should returns update query marking rows as deleted", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "UPDATE table_a SET deleted_at = now() WHERE (table_a.id = :id) AND deleted_at IS NULL")
+		})
+	})
+
+	Convey("Given delete query on soft deletable table with custom deleted value", t, func() {
+		query, err := NewDeleteQuery("table_b").
+			AddWhere(
+				"table_b.id = :id",
+			).
+			BuildQuery()
+
+		Convey("It should returns update query using custom value", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "UPDATE table_b SET removed_at = CURRENT_TIMESTAMP WHERE (table_b.id = :id) AND removed_at IS NULL")
+		})
+	})
+
+	Convey("Given hard delete query on soft deletable table", t, func() {
+		query, err := NewDeleteQuery("table_a").
+			AddWhere(
+				"table_a.id = :id",
+			).
+			HardDelete().
+			BuildQuery()
+
+		Convey("It should returns delete query", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "DELETE FROM table_a WHERE table_a.id = :id")
+		})
+	})
+
+	Convey("Given read query on soft deletable table", t, func() {
+		query, err := NewReadQuery("table_a").
+			AddSelect(
+				"field_a",
+			).
+			AddWhere(
+				"table_a.id = :id",
+			).
+			BuildQuery()
+
+		Convey("It should exclude soft deleted rows", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT field_a FROM table_a WHERE (table_a.id = :id) AND table_a.deleted_at IS NULL")
+		})
+	})
+
+	Convey("Given queries with top-level OR fragment on soft deletable table", t, func() {
+		read, readErr := NewReadQuery("table_a").
+			WithoutAutoParenthesis().
+			AddSelect("field_a").
+			AddWhere("field_a = 1 OR field_b = 2", "field_c = 3").
+			BuildQuery()
+		remove, removeErr := NewDeleteQuery("table_a").
+			WithoutAutoParenthesis().
+			AddWhere("field_a = 1 OR field_b = 2").
+			BuildQuery()
+
+		Convey("It should keep soft delete conditions outside of the fragments", func() {
+			So(readErr, ShouldBeNil)
+			So(read, ShouldEqual, "SELECT field_a FROM table_a WHERE (field_a = 1 OR field_b = 2 AND field_c = 3) AND table_a.deleted_at IS NULL")
+			So(removeErr, ShouldBeNil)
+			So(remove, ShouldEqual, "UPDATE table_a SET deleted_at = now() WHERE (field_a = 1 OR field_b = 2) AND deleted_at IS NULL")
+		})
+	})
+
+	Convey("Given read query joining soft deletable tables", t, func() {
+		query, args, err := NewReadQuery("users u").
+			AddSelect("u.id").
+			AddJoin(
+				"LEFT JOIN table_a a ON a.user_id = u.id OR a.owner_id = u.id -- owned too",
+				"JOIN table_b USING (user_id) INNER JOIN table_c c ON c.user_id = u.id",
+			).
+			AddWhereExpr(NewExpr("u.id = ?", 1)).
+			BuildQueryWithArgs()
+		withDeleted, withDeletedErr := NewReadQuery("users u").
+			AddSelect("u.id").
+			AddJoin("LEFT JOIN table_a a ON a.user_id = u.id").
+			WithDeleted().
+			BuildQuery()
+		_, usingErr := NewReadQuery("users u").
+			AddSelect("u.id").
+			AddJoin("LEFT JOIN table_a USING (user_id)").
+			BuildQuery()
+
+		Convey("It should exclude soft deleted rows in ON condition or where clause", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT u.id FROM users u "+
+				"LEFT JOIN table_a a ON (a.user_id = u.id OR a.owner_id = u.id) AND a.deleted_at IS NULL -- owned too\n"+
+				"JOIN table_b USING (user_id) INNER JOIN table_c c ON c.user_id = u.id WHERE (u.id = ?) AND table_b.removed_at IS NULL")
+			So(args, ShouldResemble, []interface{}{1})
+			So(withDeletedErr, ShouldBeNil)
+			So(withDeleted, ShouldEqual, "SELECT u.id FROM users u LEFT JOIN table_a a ON a.user_id = u.id")
+			So(usingErr, ShouldBeError, `can't exclude soft deleted rows of table "table_a" joined without ON condition`)
+		})
+	})
+
+	Convey("Given read query on aliased soft deletable tables", t, func() {
+		query, err := NewReadQuery("table_a a").
+			AddFrom("table_b AS b", "table_c").
+			AddSelect(
+				"field_a",
+			).
+			BuildQuery()
+
+		Convey("It should exclude soft deleted rows using the aliases", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT field_a FROM table_a a, table_b AS b, table_c WHERE a.deleted_at IS NULL AND b.removed_at IS NULL")
+		})
+	})
+
+	Convey("Given queries on schema qualified, quoted or differently cased soft deletable tables", t, func() {
+		RegisterSoftDelete("audit.table_c", SoftDelete{Column: "deleted_at"})
+		defer UnregisterSoftDelete("audit.table_c")
+
+		read, readErr := NewReadQuery("public.table_a").
+			AddFrom(`TABLE_B b`, `"TABLE_A" upper`, "audit.table_c c", "other.table_c o").
+			AddSelect("field_a").
+			BuildQuery()
+		remove, removeErr := NewDeleteQuery(`public."table_b"`).
+			AddWhere("id = 1").
+			BuildQuery()
+
+		Convey("It should match the registrations ignoring case of unquoted names", func() {
+			So(readErr, ShouldBeNil)
+			So(read, ShouldEqual, `SELECT field_a FROM public.table_a, TABLE_B b, "TABLE_A" upper, audit.table_c c, other.table_c o `+
+				"WHERE public.table_a.deleted_at IS NULL AND b.removed_at IS NULL AND c.deleted_at IS NULL")
+			So(removeErr, ShouldBeNil)
+			So(remove, ShouldEqual, `UPDATE public."table_b" SET removed_at = CURRENT_TIMESTAMP WHERE (id = 1) AND removed_at IS NULL`)
+		})
+	})
+
+	Convey("Given read query with deleted rows", t, func() {
+		query, err := NewReadQuery("table_a").
+			AddSelect(
+				"field_a",
+			).
+			WithDeleted().
+			BuildQuery()
+
+		Convey("It should not filter soft deleted rows", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT field_a FROM table_a")
+		})
+	})
+
+	Convey("Given read query with only deleted rows", t, func() {
+		query, err := NewReadQuery("table_a").
+			AddSelect(
+				"field_a",
+			).
+			OnlyDeleted().
+			BuildQuery()
+
+		Convey("It should returns soft deleted rows only", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT field_a FROM table_a WHERE table_a.deleted_at IS NOT NULL")
+		})
+	})
+}
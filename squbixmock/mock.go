@@ -0,0 +1,336 @@
+// Package squbixmock provides squbix.Executor for unit tests of code executing squbix builders. It
+// records every statement, answers the ones matching registered expectations with canned rows or
+// results and reports unmet expectations and unexpected statements.
+package squbixmock
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/nicklaros/squbix"
+)
+
+const (
+	methodExec  = "Exec"
+	methodQuery = "Query"
+)
+
+// Call is statement executed through the mock.
+type Call struct {
+	Method string
+	SQL    string
+	Args   []interface{}
+	Shape  squbix.Shape
+}
+
+// String returns description of the call for reports.
+func (ths Call) String() string {
+	return fmt.Sprintf("%s %s with args %v", ths.Method, ths.SQL, ths.Args)
+}
+
+// Mock is squbix.Executor answering statements with expectations registered by the test.
+type Mock struct {
+	mutex        sync.Mutex
+	expectations []*Expectation
+	calls        []Call
+	unexpected   []Call
+}
+
+// New creates mock without expectations.
+func New() *Mock {
+	return &Mock{}
+}
+
+// ExpectQuery registers expectation of statement executed using Query.
+func (ths *Mock) ExpectQuery() *Expectation {
+	return ths.expect(methodQuery)
+}
+
+// ExpectExec registers expectation of statement executed using Exec.
+func (ths *Mock) ExpectExec() *Expectation {
+	return ths.expect(methodExec)
+}
+
+func (ths *Mock) expect(method string) *Expectation {
+	ths.mutex.Lock()
+	defer ths.mutex.Unlock()
+
+	expectation := &Expectation{
+		method: method,
+		result: Result{},
+		times:  1,
+	}
+	ths.expectations = append(ths.expectations, expectation)
+
+	return expectation
+}
+
+// Exec executes statement of builder answering it with matching expectation.
+func (ths *Mock) Exec(ctx context.Context, builder squbix.Builder) (sql.Result, error) {
+	expectation, err := ths.call(ctx, methodExec, builder)
+	if err != nil {
+		return nil, err
+	}
+
+	return expectation.result, nil
+}
+
+// Query executes statement of builder answering it with rows of matching expectation.
+func (ths *Mock) Query(ctx context.Context, builder squbix.Builder) (squbix.Rows, error) {
+	expectation, err := ths.call(ctx, methodQuery, builder)
+	if err != nil {
+		return nil, err
+	}
+
+	if expectation.rows == nil {
+		return NewRows(), nil
+	}
+
+	return expectation.rows.fresh(), nil
+}
+
+func (ths *Mock) call(ctx context.Context, method string, builder squbix.Builder) (*Expectation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	query, args, err := builder.BuildQueryWithArgs()
+	if err != nil {
+		return nil, err
+	}
+
+	call := Call{
+		Method: method,
+		SQL:    query,
+		Args:   args,
+		Shape:  squbix.ShapeOf(builder),
+	}
+
+	ths.mutex.Lock()
+	defer ths.mutex.Unlock()
+
+	ths.calls = append(ths.calls, call)
+
+	for _, expectation := range ths.expectations {
+		if expectation.calls < expectation.times && expectation.matches(call) {
+			expectation.calls++
+
+			return expectation, expectation.err
+		}
+	}
+
+	ths.unexpected = append(ths.unexpected, call)
+
+	return nil, fmt.Errorf("squbixmock: unexpected %s", call)
+}
+
+// Calls returns every statement executed through the mock in order.
+func (ths *Mock) Calls() []Call {
+	ths.mutex.Lock()
+	defer ths.mutex.Unlock()
+
+	return append([]Call{}, ths.calls...)
+}
+
+// ExpectationsWereMet returns error describing expectations called less times than expected and
+// statements no expectation matched.
+func (ths *Mock) ExpectationsWereMet() error {
+	ths.mutex.Lock()
+	defer ths.mutex.Unlock()
+
+	problems := []string{}
+
+	for _, expectation := range ths.expectations {
+		if expectation.calls < expectation.times {
+			problems = append(problems, fmt.Sprintf("unmet expectation: %s", expectation))
+		}
+	}
+
+	for _, call := range ths.unexpected {
+		problems = append(problems, fmt.Sprintf("unexpected %s", call))
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("squbixmock: %s", strings.Join(problems, "; "))
+}
+
+// AssertExpectations reports result of ExpectationsWereMet as test error.
+func (ths *Mock) AssertExpectations(t testing.TB) {
+	t.Helper()
+
+	if err := ths.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// Expectation is statement the test expects to be executed and the answer to it.
+type Expectation struct {
+	method  string
+	pattern *regexp.Regexp
+	shape   *squbix.Shape
+	args    []interface{}
+	argsSet bool
+	rows    *Rows
+	result  sql.Result
+	err     error
+	times   int
+	calls   int
+}
+
+// WithSQL matches statements whose sql matches the regular expression.
+func (ths *Expectation) WithSQL(pattern string) *Expectation {
+	ths.pattern = regexp.MustCompile(pattern)
+
+	return ths
+}
+
+// WithShape matches statements of the kind on the table having at least the conditions, written
+// as they were added to the builder.
+func (ths *Expectation) WithShape(kind squbix.StatementKind, table string, conditions ...string) *Expectation {
+	ths.shape = &squbix.Shape{
+		Kind:       kind,
+		Table:      table,
+		Conditions: conditions,
+	}
+
+	return ths
+}
+
+// WithArgs matches statements bound exactly to the arguments.
+func (ths *Expectation) WithArgs(args ...interface{}) *Expectation {
+	ths.args = args
+	ths.argsSet = true
+
+	return ths
+}
+
+// WillReturnRows answers the query with the rows.
+func (ths *Expectation) WillReturnRows(rows *Rows) *Expectation {
+	ths.rows = rows
+
+	return ths
+}
+
+// WillReturnResult answers the exec with last insert id and number of affected rows.
+func (ths *Expectation) WillReturnResult(lastInsertID int64, rowsAffected int64) *Expectation {
+	ths.result = Result{
+		lastInsertID: lastInsertID,
+		rowsAffected: rowsAffected,
+	}
+
+	return ths
+}
+
+// WillReturnError answers the statement with error.
+func (ths *Expectation) WillReturnError(err error) *Expectation {
+	ths.err = err
+
+	return ths
+}
+
+// Times sets how many statements the expectation answers, it answers one by default.
+func (ths *Expectation) Times(times int) *Expectation {
+	ths.times = times
+
+	return ths
+}
+
+// String returns description of the expectation for reports.
+func (ths *Expectation) String() string {
+	fragments := []string{ths.method}
+
+	if ths.pattern != nil {
+		fragments = append(fragments, fmt.Sprintf("matching %q", ths.pattern))
+	}
+
+	if ths.shape != nil {
+		fragments = append(fragments, fmt.Sprintf("of %s on %s with conditions %q", ths.shape.Kind, ths.shape.Table, ths.shape.Conditions))
+	}
+
+	if ths.argsSet {
+		fragments = append(fragments, fmt.Sprintf("with args %v", ths.args))
+	}
+
+	fragments = append(fragments, fmt.Sprintf("called %d of %d time(s)", ths.calls, ths.times))
+
+	return strings.Join(fragments, " ")
+}
+
+func (ths *Expectation) matches(call Call) bool {
+	if ths.method != call.Method {
+		return false
+	}
+	if ths.pattern != nil && !ths.pattern.MatchString(call.SQL) {
+		return false
+	}
+	if ths.shape != nil && !shapeMatches(*ths.shape, call.Shape) {
+		return false
+	}
+	if ths.argsSet && !argsMatch(ths.args, call.Args) {
+		return false
+	}
+
+	return true
+}
+
+func shapeMatches(expected squbix.Shape, actual squbix.Shape) bool {
+	if expected.Kind != actual.Kind || expected.Table != actual.Table {
+		return false
+	}
+
+	conditions := map[string]bool{}
+	for _, condition := range actual.Conditions {
+		conditions[normalize(condition)] = true
+	}
+
+	for _, condition := range expected.Conditions {
+		if !conditions[normalize(condition)] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func argsMatch(expected []interface{}, actual []interface{}) bool {
+	if len(expected) != len(actual) {
+		return false
+	}
+
+	for index := range expected {
+		if !reflect.DeepEqual(expected[index], actual[index]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func normalize(condition string) string {
+	return strings.Join(strings.Fields(condition), " ")
+}
+
+// Result is canned result of exec expectation.
+type Result struct {
+	lastInsertID int64
+	rowsAffected int64
+}
+
+// LastInsertId returns last insert id the expectation was given.
+func (ths Result) LastInsertId() (int64, error) {
+	return ths.lastInsertID, nil
+}
+
+// RowsAffected returns number of affected rows the expectation was given.
+func (ths Result) RowsAffected() (int64, error) {
+	return ths.rowsAffected, nil
+}
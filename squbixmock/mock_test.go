@@ -0,0 +1,147 @@
+package squbixmock
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nicklaros/squbix"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type order struct {
+	ID     int64
+	Status string
+	Note   *string
+}
+
+// orderRepository is application code under test.
+type orderRepository struct {
+	executor squbix.Executor
+}
+
+func (ths *orderRepository) paid(ctx context.Context, customerID int64) ([]order, error) {
+	rows, err := ths.executor.Query(ctx, squbix.NewReadQuery("orders o").
+		AddSelect("o.id", "o.status", "o.note").
+		AddWhereExpr(squbix.NewExpr("o.customer_id = ?", customerID), squbix.NewExpr("o.status = ?", "paid")).
+		AddOrderBy("o.id"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := []order{}
+	for rows.Next() {
+		current := order{}
+		if err := rows.Scan(&current.ID, &current.Status, &current.Note); err != nil {
+			return nil, err
+		}
+
+		orders = append(orders, current)
+	}
+
+	return orders, rows.Err()
+}
+
+func (ths *orderRepository) cancel(ctx context.Context, id int64) (int64, error) {
+	result, err := ths.executor.Exec(ctx, squbix.NewUpdateQuery("orders").
+		AddSetFieldExpr(squbix.NewExpr("status = ?", "cancelled")).
+		AddWhereExpr(squbix.NewExpr("id = ?", id)))
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func TestMock(t *testing.T) {
+	Convey("Given query expected by builder shape", t, func() {
+		mock := New()
+		note := "gift"
+		mock.ExpectQuery().
+			WithShape(squbix.SelectStatement, "orders", "o.status = ?").
+			WithArgs(int64(7), "paid").
+			WillReturnRows(NewRows("id", "status", "note").AddRow(int64(1), "paid", nil).AddRow(2, []byte("paid"), note))
+
+		repository := &orderRepository{executor: mock}
+		orders, err := repository.paid(context.Background(), 7)
+
+		Convey("It should answer with canned rows and record the statement", func() {
+			So(err, ShouldBeNil)
+			So(orders, ShouldResemble, []order{{ID: 1, Status: "paid"}, {ID: 2, Status: "paid", Note: &note}})
+			So(mock.ExpectationsWereMet(), ShouldBeNil)
+			So(mock.Calls(), ShouldHaveLength, 1)
+			So(mock.Calls()[0].SQL, ShouldEqual, "SELECT o.id, o.status, o.note FROM orders o WHERE o.customer_id = ? AND o.status = ? ORDER BY o.id")
+			So(mock.Calls()[0].Shape, ShouldResemble, squbix.Shape{
+				Kind:       squbix.SelectStatement,
+				Table:      "orders",
+				Conditions: []string{"o.customer_id = ?", "o.status = ?"},
+			})
+		})
+	})
+
+	Convey("Given exec expected by sql pattern several times", t, func() {
+		mock := New()
+		mock.ExpectExec().
+			WithSQL(`^UPDATE orders SET status = \? WHERE id = \?$`).
+			WillReturnResult(0, 1).
+			Times(2)
+
+		repository := &orderRepository{executor: mock}
+		first, firstErr := repository.cancel(context.Background(), 1)
+		second, secondErr := repository.cancel(context.Background(), 2)
+
+		Convey("It should answer each of them with canned result", func() {
+			So(firstErr, ShouldBeNil)
+			So(first, ShouldEqual, 1)
+			So(secondErr, ShouldBeNil)
+			So(second, ShouldEqual, 1)
+			So(mock.ExpectationsWereMet(), ShouldBeNil)
+		})
+	})
+
+	Convey("Given expectation answering with error", t, func() {
+		mock := New()
+		failure := errors.New("connection reset")
+		mock.ExpectExec().WithShape(squbix.UpdateStatement, "orders").WillReturnError(failure)
+
+		_, err := (&orderRepository{executor: mock}).cancel(context.Background(), 1)
+
+		Convey("It should returns the error", func() {
+			So(err, ShouldEqual, failure)
+			So(mock.ExpectationsWereMet(), ShouldBeNil)
+		})
+	})
+
+	Convey("Given unmet expectation and unexpected statement", t, func() {
+		mock := New()
+		mock.ExpectQuery().WithShape(squbix.SelectStatement, "customers")
+		mock.ExpectExec().WithSQL("^DELETE").WithArgs(int64(1))
+
+		_, err := (&orderRepository{executor: mock}).cancel(context.Background(), 1)
+
+		Convey("It should report both", func() {
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "squbixmock: unexpected Exec UPDATE orders SET status = ? WHERE id = ? with args [cancelled 1]")
+			So(mock.ExpectationsWereMet().Error(), ShouldEqual, `squbixmock: unmet expectation: Query of SELECT on customers with conditions [] called 0 of 1 time(s); `+
+				`unmet expectation: Exec matching "^DELETE" with args [1] called 0 of 1 time(s); `+
+				`unexpected Exec UPDATE orders SET status = ? WHERE id = ? with args [cancelled 1]`)
+		})
+	})
+
+	Convey("Given builder which can't be built or canceled context", t, func() {
+		mock := New()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, buildErr := mock.Query(context.Background(), squbix.NewReadQuery("orders"))
+		_, ctxErr := mock.Exec(ctx, squbix.NewDeleteQuery("orders"))
+
+		Convey("It should returns the error without recording the statement", func() {
+			So(buildErr, ShouldNotBeNil)
+			So(buildErr.Error(), ShouldEqual, "no field selected, add it using AddSelect method")
+			So(ctxErr, ShouldEqual, context.Canceled)
+			So(mock.Calls(), ShouldBeEmpty)
+		})
+	})
+}
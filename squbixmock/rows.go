@@ -0,0 +1,171 @@
+package squbixmock
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Rows is canned result set returned by query expectation.
+type Rows struct {
+	columns  []string
+	values   [][]interface{}
+	position int
+	err      error
+	closed   bool
+}
+
+// NewRows creates empty result set with the columns.
+func NewRows(columns ...string) *Rows {
+	return &Rows{
+		columns: columns,
+	}
+}
+
+// AddRow adds row of values in order of columns.
+func (ths *Rows) AddRow(values ...interface{}) *Rows {
+	if len(values) != len(ths.columns) && ths.err == nil {
+		ths.err = fmt.Errorf("squbixmock: row has %d value(s) but %d column(s)", len(values), len(ths.columns))
+	}
+
+	ths.values = append(ths.values, values)
+
+	return ths
+}
+
+// Columns returns names of the columns.
+func (ths *Rows) Columns() ([]string, error) {
+	if ths.closed {
+		return nil, errors.New("squbixmock: rows are closed")
+	}
+
+	return ths.columns, nil
+}
+
+// Next prepares next row for Scan, it returns false when there is no more row or rows are invalid.
+func (ths *Rows) Next() bool {
+	if ths.closed || ths.err != nil || ths.position >= len(ths.values) {
+		return false
+	}
+
+	ths.position++
+
+	return true
+}
+
+// Scan copies values of current row into dest.
+func (ths *Rows) Scan(dest ...interface{}) error {
+	if ths.closed {
+		return errors.New("squbixmock: rows are closed")
+	}
+	if ths.position == 0 || ths.position > len(ths.values) {
+		return errors.New("squbixmock: Scan called without calling Next")
+	}
+
+	row := ths.values[ths.position-1]
+	if len(dest) != len(row) {
+		return fmt.Errorf("squbixmock: expected %d destination argument(s) in Scan, not %d", len(row), len(dest))
+	}
+
+	for index, value := range row {
+		if err := assign(dest[index], value); err != nil {
+			return fmt.Errorf("squbixmock: scanning column %q: %s", ths.columns[index], err)
+		}
+	}
+
+	return nil
+}
+
+// Err returns error of invalid rows.
+func (ths *Rows) Err() error {
+	return ths.err
+}
+
+// Close closes the rows.
+func (ths *Rows) Close() error {
+	ths.closed = true
+
+	return nil
+}
+
+// fresh returns unread copy of the rows so expectation can return them more than once.
+func (ths *Rows) fresh() *Rows {
+	return &Rows{
+		columns: ths.columns,
+		values:  ths.values,
+		err:     ths.err,
+	}
+}
+
+// assign stores value into dest pointer converting between numeric types and between strings and
+// bytes, like database/sql does for driver values.
+func assign(dest interface{}, value interface{}) error {
+	if scanner, ok := dest.(sql.Scanner); ok {
+		return scanner.Scan(value)
+	}
+
+	pointer := reflect.ValueOf(dest)
+	if pointer.Kind() != reflect.Ptr || pointer.IsNil() {
+		return fmt.Errorf("destination must be non-nil pointer, got %T", dest)
+	}
+
+	return assignValue(pointer.Elem(), value)
+}
+
+func assignValue(target reflect.Value, value interface{}) error {
+	if value == nil {
+		switch target.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+			target.Set(reflect.Zero(target.Type()))
+
+			return nil
+		}
+
+		return fmt.Errorf("converting NULL to %s is unsupported", target.Type())
+	}
+
+	source := reflect.ValueOf(value)
+
+	switch {
+	case source.Type().AssignableTo(target.Type()):
+		target.Set(source)
+	case target.Kind() == reflect.Ptr:
+		element := reflect.New(target.Type().Elem())
+		if err := assignValue(element.Elem(), value); err != nil {
+			return err
+		}
+
+		target.Set(element)
+	case convertible(source.Type(), target.Type()):
+		target.Set(source.Convert(target.Type()))
+	default:
+		return fmt.Errorf("converting %T to %s is unsupported", value, target.Type())
+	}
+
+	return nil
+}
+
+func convertible(source reflect.Type, target reflect.Type) bool {
+	if isNumeric(source.Kind()) && isNumeric(target.Kind()) {
+		return true
+	}
+
+	return isText(source) && isText(target)
+}
+
+func isNumeric(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+
+	return false
+}
+
+func isText(valueType reflect.Type) bool {
+	return valueType.Kind() == reflect.String ||
+		valueType.Kind() == reflect.Slice && valueType.Elem().Kind() == reflect.Uint8
+}
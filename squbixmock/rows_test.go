@@ -0,0 +1,62 @@
+package squbixmock
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRows(t *testing.T) {
+	Convey("Given rows with values of other types than destinations", t, func() {
+		rows := NewRows("id", "total", "code").AddRow(1, 2.5, "A")
+
+		var id int64
+		var total float32
+		var code []byte
+		var anything interface{}
+
+		So(rows.Next(), ShouldBeTrue)
+		err := rows.Scan(&id, &total, &code)
+		anyErr := rows.Scan(&id, &anything, &code)
+
+		Convey("It should convert the values", func() {
+			So(err, ShouldBeNil)
+			So(id, ShouldEqual, 1)
+			So(total, ShouldEqual, 2.5)
+			So(string(code), ShouldEqual, "A")
+			So(anyErr, ShouldBeNil)
+			So(anything, ShouldEqual, 2.5)
+			So(rows.Next(), ShouldBeFalse)
+		})
+	})
+
+	Convey("Given values which can't be scanned", t, func() {
+		rows := NewRows("id", "code").AddRow(nil, 1)
+
+		var id int64
+		var code string
+
+		scanBeforeNext := rows.Scan(&id, &code)
+		rows.Next()
+		nullErr := rows.Scan(&id, &code)
+		countErr := rows.Scan(&id)
+		rows.Close()
+		closedErr := rows.Scan(&id, &code)
+
+		Convey("It should returns error", func() {
+			So(scanBeforeNext.Error(), ShouldEqual, "squbixmock: Scan called without calling Next")
+			So(nullErr.Error(), ShouldEqual, `squbixmock: scanning column "id": converting NULL to int64 is unsupported`)
+			So(countErr.Error(), ShouldEqual, "squbixmock: expected 2 destination argument(s) in Scan, not 1")
+			So(closedErr.Error(), ShouldEqual, "squbixmock: rows are closed")
+		})
+	})
+
+	Convey("Given row with wrong number of values", t, func() {
+		rows := NewRows("id", "code").AddRow(1)
+
+		Convey("It should report it as rows error", func() {
+			So(rows.Next(), ShouldBeFalse)
+			So(rows.Err().Error(), ShouldEqual, "squbixmock: row has 1 value(s) but 2 column(s)")
+		})
+	})
+}
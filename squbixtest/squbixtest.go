@@ -0,0 +1,166 @@
+// Package squbixtest provides golden file assertions for queries generated by squbix builders.
+//
+// Golden files hold pretty printed query followed by its arguments, run tests with
+// -squbixtest.update flag, or SQUBIXTEST_UPDATE=1 environment variable when testing many packages
+// at once, to regenerate them from current builders.
+package squbixtest
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/nicklaros/squbix"
+)
+
+const (
+	argsHeader = "-- args:"
+	updateEnv  = "SQUBIXTEST_UPDATE"
+)
+
+// update is namespaced, so it never clashes with -update flag of packages using squbixtest.
+var update = flag.Bool("squbixtest.update", false, "update squbixtest golden files")
+
+// AssertSQL checks query and arguments generated by builder match golden file, whitespace outside
+// of quotes is insignificant. When updating golden files it is written instead.
+func AssertSQL(t testing.TB, builder squbix.Builder, golden string) bool {
+	t.Helper()
+
+	query, args, err := builder.BuildQueryWithArgs()
+	if err != nil {
+		t.Errorf("squbixtest: building query for %s: %s", golden, err)
+
+		return false
+	}
+
+	actual := formatGolden(query, args)
+
+	if updating() {
+		if err := os.MkdirAll(filepath.Dir(golden), 0755); err != nil {
+			t.Errorf("squbixtest: %s", err)
+
+			return false
+		}
+		if err := ioutil.WriteFile(golden, []byte(actual), 0644); err != nil {
+			t.Errorf("squbixtest: %s", err)
+
+			return false
+		}
+
+		return true
+	}
+
+	content, err := ioutil.ReadFile(golden)
+	if os.IsNotExist(err) {
+		t.Errorf("squbixtest: golden file %s doesn't exist, run tests with -squbixtest.update flag to create it", golden)
+
+		return false
+	}
+	if err != nil {
+		t.Errorf("squbixtest: %s", err)
+
+		return false
+	}
+
+	expected := string(content)
+	expectedQuery, expectedArgs := splitGolden(expected)
+	actualQuery, actualArgs := splitGolden(actual)
+
+	if normalize(expectedQuery) != normalize(actualQuery) || expectedArgs != actualArgs {
+		t.Errorf("squbixtest: query doesn't match golden file %s\n\nexpected:\n%s\nactual:\n%s", golden, expected, actual)
+
+		return false
+	}
+
+	return true
+}
+
+func updating() bool {
+	return *update || os.Getenv(updateEnv) == "1"
+}
+
+// formatGolden returns content of golden file for query and arguments.
+func formatGolden(query string, args []interface{}) string {
+	content := &strings.Builder{}
+	content.WriteString(squbix.PrettyPrint(query))
+	content.WriteString("\n")
+
+	if len(args) > 0 {
+		content.WriteString(argsHeader + "\n")
+
+		for index, arg := range args {
+			content.WriteString(fmt.Sprintf("-- %d: %T %s\n", index+1, arg, formatArg(arg)))
+		}
+	}
+
+	return content.String()
+}
+
+func formatArg(arg interface{}) string {
+	switch typed := arg.(type) {
+	case string:
+		return fmt.Sprintf("%q", typed)
+	case []byte:
+		return fmt.Sprintf("%x", typed)
+	case fmt.Stringer:
+		return typed.String()
+	}
+
+	return fmt.Sprintf("%v", arg)
+}
+
+// splitGolden splits golden file content into query and normalized argument lines.
+func splitGolden(content string) (string, string) {
+	query := content
+	args := ""
+
+	if index := strings.Index(content, "\n"+argsHeader+"\n"); index >= 0 {
+		query = content[:index]
+		args = strings.TrimSpace(content[index+len(argsHeader)+2:])
+	}
+
+	return query, args
+}
+
+// normalize collapses whitespace outside of quotes and drops it around parentheses and commas.
+func normalize(query string) string {
+	normalized := &strings.Builder{}
+	quote := rune(0)
+	previous := rune(0)
+	space := false
+
+	write := func(char rune) {
+		if space && previous != 0 && !strings.ContainsRune("(,", previous) && !strings.ContainsRune("),", char) {
+			normalized.WriteRune(' ')
+		}
+
+		space = false
+		previous = char
+		normalized.WriteRune(char)
+	}
+
+	for _, char := range query {
+		switch {
+		case quote != 0:
+			normalized.WriteRune(char)
+			previous = char
+			if char == quote {
+				quote = 0
+			}
+		case char == '\'' || char == '"' || char == '`':
+			write(char)
+			quote = char
+		case unicode.IsSpace(char):
+			space = true
+		default:
+			write(char)
+		}
+	}
+
+	return normalized.String()
+}
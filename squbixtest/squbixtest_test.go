@@ -0,0 +1,129 @@
+package squbixtest
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nicklaros/squbix"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// updateOwnGolden is -update flag the way packages using squbixtest commonly declare it, it must not
+// clash with the flag of squbixtest.
+var updateOwnGolden = flag.Bool("update", false, "update golden files of the package")
+
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (ths *recorder) Helper() {}
+
+func (ths *recorder) Errorf(format string, args ...interface{}) {
+	ths.errors = append(ths.errors, fmt.Sprintf(format, args...))
+}
+
+func paidOrders(status string) *squbix.ReadQueryBuilder {
+	return squbix.NewReadQuery("orders").
+		AddSelect("id", "total").
+		AddWhereExpr(
+			squbix.NewExpr("status = ?", status),
+			squbix.NewExpr("customer_id IN (?)", []int64{7, 8}),
+		).
+		AddOrderBy("id")
+}
+
+func TestAssertSQL(t *testing.T) {
+	Convey("Given golden file with different whitespace", t, func() {
+		recorded := &recorder{TB: t}
+
+		Convey("It should pass", func() {
+			So(AssertSQL(recorded, paidOrders("paid"), "testdata/paid_orders.sql"), ShouldBeTrue)
+			So(recorded.errors, ShouldBeEmpty)
+		})
+	})
+
+	Convey("Given builder generating different arguments or query", t, func() {
+		argsRecorded := &recorder{TB: t}
+		queryRecorded := &recorder{TB: t}
+
+		argsPassed := AssertSQL(argsRecorded, paidOrders("refunded"), "testdata/paid_orders.sql")
+		queryPassed := AssertSQL(queryRecorded, paidOrders("paid").AddLimit(1), "testdata/paid_orders.sql")
+
+		Convey("It should fail", func() {
+			So(argsPassed, ShouldBeFalse)
+			So(argsRecorded.errors, ShouldHaveLength, 1)
+			So(argsRecorded.errors[0], ShouldContainSubstring, `-- 1: string "refunded"`)
+			So(queryPassed, ShouldBeFalse)
+			So(queryRecorded.errors, ShouldHaveLength, 1)
+			So(queryRecorded.errors[0], ShouldContainSubstring, "query doesn't match golden file testdata/paid_orders.sql")
+		})
+	})
+
+	Convey("Given missing golden file or invalid builder", t, func() {
+		missingRecorded := &recorder{TB: t}
+		invalidRecorded := &recorder{TB: t}
+
+		missingPassed := AssertSQL(missingRecorded, paidOrders("paid"), "testdata/missing.sql")
+		invalidPassed := AssertSQL(invalidRecorded, squbix.NewReadQuery("orders"), "testdata/paid_orders.sql")
+
+		Convey("It should fail", func() {
+			So(missingPassed, ShouldBeFalse)
+			So(missingRecorded.errors, ShouldResemble, []string{"squbixtest: golden file testdata/missing.sql doesn't exist, run tests with -squbixtest.update flag to create it"})
+			So(invalidPassed, ShouldBeFalse)
+			So(invalidRecorded.errors, ShouldResemble, []string{"squbixtest: building query for testdata/paid_orders.sql: no field selected, add it using AddSelect method"})
+		})
+	})
+
+	Convey("Given -squbixtest.update flag", t, func() {
+		directory, err := ioutil.TempDir("", "squbixtest")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(directory)
+
+		So(flag.Set("squbixtest.update", "true"), ShouldBeNil)
+		defer flag.Set("squbixtest.update", "false")
+
+		golden := filepath.Join(directory, "nested", "paid_orders.sql")
+		passed := AssertSQL(t, paidOrders("paid"), golden)
+		content, err := ioutil.ReadFile(golden)
+
+		Convey("It should write pretty printed query and arguments to golden file", func() {
+			So(passed, ShouldBeTrue)
+			So(err, ShouldBeNil)
+			So(string(content), ShouldEqual, `SELECT
+  id,
+  total
+FROM orders
+WHERE status = ? AND customer_id IN (?, ?)
+ORDER BY id
+-- args:
+-- 1: string "paid"
+-- 2: int64 7
+-- 3: int64 8
+`)
+		})
+	})
+
+	Convey("Given SQUBIXTEST_UPDATE environment variable", t, func() {
+		directory, err := ioutil.TempDir("", "squbixtest")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(directory)
+
+		So(os.Setenv("SQUBIXTEST_UPDATE", "1"), ShouldBeNil)
+		defer os.Unsetenv("SQUBIXTEST_UPDATE")
+
+		golden := filepath.Join(directory, "paid_orders.sql")
+		passed := AssertSQL(t, paidOrders("paid"), golden)
+		_, err = os.Stat(golden)
+
+		Convey("It should write golden file regardless of -update flag of the package", func() {
+			So(*updateOwnGolden, ShouldBeFalse)
+			So(passed, ShouldBeTrue)
+			So(err, ShouldBeNil)
+		})
+	})
+}
@@ -0,0 +1,7 @@
+SELECT id,   total FROM orders
+WHERE status = ? AND customer_id IN ( ?, ? )
+ORDER BY id
+-- args:
+-- 1: string "paid"
+-- 2: int64 7
+-- 3: int64 8
@@ -0,0 +1,139 @@
+package squbix
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// QueryTemplate is named read query made of base builder and clauses switched on by named
+// inputs, so near identical queries can share single definition.
+type QueryTemplate struct {
+	name    string
+	base    func() *queryBuilder
+	clauses []templateClause
+}
+
+type templateClause struct {
+	input    string
+	required bool
+	apply    func(builder *queryBuilder, value interface{}) error
+}
+
+// NewQueryTemplate creates new query template, base is called on every render to create builder
+// the clauses are added to.
+func NewQueryTemplate(name string, base func() *queryBuilder) *QueryTemplate {
+	return &QueryTemplate{
+		name: name,
+		base: base,
+	}
+}
+
+// Name returns name of the template.
+func (ths *QueryTemplate) Name() string {
+	return ths.name
+}
+
+// AddWhere adds where clause applied only when input is supplied, input value is bound to every
+// placeholder of the clause.
+func (ths *QueryTemplate) AddWhere(input string, where string) *QueryTemplate {
+	ths.clauses = append(ths.clauses, templateClause{
+		input: input,
+		apply: bindWhere(where),
+	})
+
+	return ths
+}
+
+// AddRequiredWhere adds where clause whose input must always be supplied.
+func (ths *QueryTemplate) AddRequiredWhere(input string, where string) *QueryTemplate {
+	ths.clauses = append(ths.clauses, templateClause{
+		input:    input,
+		required: true,
+		apply:    bindWhere(where),
+	})
+
+	return ths
+}
+
+// AddOrderBy adds order by clause applied only when input is supplied, input value selects one of
+// the orderings by its key so callers can't inject arbitrary sql.
+func (ths *QueryTemplate) AddOrderBy(input string, orderings map[string]string) *QueryTemplate {
+	ths.clauses = append(ths.clauses, templateClause{
+		input: input,
+		apply: func(builder *queryBuilder, value interface{}) error {
+			key, ok := value.(string)
+			if !ok {
+				return fmt.Errorf("input %q must be string, got %T", input, value)
+			}
+
+			orderBy, ok := orderings[key]
+			if !ok {
+				return fmt.Errorf("input %q has unknown ordering %q", input, key)
+			}
+
+			builder.AddOrderBy(orderBy)
+
+			return nil
+		},
+	})
+
+	return ths
+}
+
+// Render generates query from inputs, clauses whose inputs are absent, nil or nil pointer are
+// omitted and inputs of other pointers are dereferenced. It reports missing required inputs and
+// inputs no clause uses.
+func (ths *QueryTemplate) Render(inputs map[string]interface{}) (string, []interface{}, error) {
+	builder := ths.base()
+	used := map[string]bool{}
+
+	for _, clause := range ths.clauses {
+		used[clause.input] = true
+
+		value, ok := inputs[clause.input]
+		if !ok || isNilValue(value) {
+			if clause.required {
+				return "", nil, fmt.Errorf("query template %q: missing input %q", ths.name, clause.input)
+			}
+
+			continue
+		}
+
+		if reflected := reflect.ValueOf(value); reflected.Kind() == reflect.Ptr {
+			value = reflected.Elem().Interface()
+		}
+
+		if err := clause.apply(builder, value); err != nil {
+			return "", nil, fmt.Errorf("query template %q: %s", ths.name, err)
+		}
+	}
+
+	unused := []string{}
+	for input := range inputs {
+		if !used[input] {
+			unused = append(unused, input)
+		}
+	}
+
+	if len(unused) > 0 {
+		sort.Strings(unused)
+
+		return "", nil, fmt.Errorf("query template %q: unused input(s) %q", ths.name, unused)
+	}
+
+	query, args, err := builder.BuildQueryWithArgs()
+	if err != nil {
+		return "", nil, fmt.Errorf("query template %q: %s", ths.name, err)
+	}
+
+	return query, args, nil
+}
+
+func bindWhere(where string) func(builder *queryBuilder, value interface{}) error {
+	return func(builder *queryBuilder, value interface{}) error {
+		builder.AddWhereExpr(bindEach(where, value))
+
+		return nil
+	}
+}
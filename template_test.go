@@ -0,0 +1,121 @@
+package squbix
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestQueryTemplate(t *testing.T) {
+	template := NewQueryTemplate("search_orders", func() *queryBuilder {
+		return NewReadQuery("orders").
+			AddSelect("id", "status")
+	}).
+		AddRequiredWhere("customer_id", "customer_id = ?").
+		AddWhere("status", "status = ?").
+		AddWhere("term", "(code ILIKE ? OR note ILIKE ?)").
+		AddOrderBy("sort", map[string]string{
+			"newest": "created_at DESC",
+			"code":   "code ASC",
+		})
+
+	Convey("Given only required input", t, func() {
+		query, args, err := template.Render(map[string]interface{}{
+			"customer_id": 10,
+		})
+
+		Convey("It should omit optional clauses", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT id, status FROM orders WHERE customer_id = ?")
+			So(args, ShouldResemble, []interface{}{10})
+		})
+	})
+
+	Convey("Given all inputs", t, func() {
+		query, args, err := template.Render(map[string]interface{}{
+			"customer_id": 10,
+			"status":      "paid",
+			"term":        "%abc%",
+			"sort":        "newest",
+		})
+
+		Convey("It should add clauses binding input to each placeholder", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT id, status FROM orders WHERE customer_id = ? AND status = ? AND (code ILIKE ? OR note ILIKE ?) ORDER BY created_at DESC")
+			So(args, ShouldResemble, []interface{}{10, "paid", "%abc%", "%abc%"})
+		})
+	})
+
+	Convey("Given nil optional input", t, func() {
+		query, args, err := template.Render(map[string]interface{}{
+			"customer_id": 10,
+			"status":      nil,
+		})
+
+		Convey("It should treat it as absent", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT id, status FROM orders WHERE customer_id = ?")
+			So(args, ShouldResemble, []interface{}{10})
+		})
+	})
+
+	Convey("Given nil and non-nil pointer inputs", t, func() {
+		status := (*string)(nil)
+		ordering := "code"
+
+		query, args, err := template.Render(map[string]interface{}{
+			"customer_id": 10,
+			"status":      status,
+			"sort":        &ordering,
+		})
+		_, _, requiredErr := template.Render(map[string]interface{}{
+			"customer_id": (*int)(nil),
+		})
+
+		Convey("It should treat nil pointer as absent and bind value of the other", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT id, status FROM orders WHERE customer_id = ? ORDER BY code ASC")
+			So(args, ShouldResemble, []interface{}{10})
+			So(requiredErr, ShouldBeError, `query template "search_orders": missing input "customer_id"`)
+		})
+	})
+
+	Convey("Given missing required input", t, func() {
+		query, _, err := template.Render(map[string]interface{}{
+			"status": "paid",
+		})
+
+		Convey("It should returns error", func() {
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, `query template "search_orders": missing input "customer_id"`)
+			So(query, ShouldEqual, "")
+		})
+	})
+
+	Convey("Given unused inputs", t, func() {
+		query, _, err := template.Render(map[string]interface{}{
+			"customer_id": 10,
+			"stauts":      "paid",
+			"limit":       10,
+		})
+
+		Convey("It should returns error", func() {
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, `query template "search_orders": unused input(s) ["limit" "stauts"]`)
+			So(query, ShouldEqual, "")
+		})
+	})
+
+	Convey("Given unknown ordering", t, func() {
+		query, _, err := template.Render(map[string]interface{}{
+			"customer_id": 10,
+			"sort":        "id; DROP TABLE orders",
+		})
+
+		Convey("It should returns error", func() {
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, `query template "search_orders": input "sort" has unknown ordering "id; DROP TABLE orders"`)
+			So(query, ShouldEqual, "")
+		})
+	})
+}
@@ -0,0 +1,41 @@
+--
+-- PostgreSQL database dump
+--
+
+SET statement_timeout = 0;
+
+CREATE FUNCTION public.touch() RETURNS trigger
+    LANGUAGE plpgsql
+    AS $$
+BEGIN
+    NEW.updated_at = now(); -- keep; semicolons inside body
+    RETURN NEW;
+END;
+$$;
+
+CREATE TABLE public.users (
+    id bigint NOT NULL,
+    email character varying(255) NOT NULL,
+    "display name" text,
+    balance numeric(12,2) DEFAULT 0 NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    deleted_at timestamp with time zone
+);
+
+CREATE TABLE IF NOT EXISTS orders (
+    id serial PRIMARY KEY,
+    user_id bigint NOT NULL REFERENCES users (id),
+    code text UNIQUE,
+    note text DEFAULT 'a, b; c',
+    CONSTRAINT orders_user_code_key UNIQUE (user_id, code),
+    CHECK (id > 0)
+);
+
+ALTER TABLE ONLY public.users
+    ADD CONSTRAINT users_pkey PRIMARY KEY (id);
+
+ALTER TABLE users ADD COLUMN nickname text;
+
+CREATE UNIQUE INDEX users_email_key ON public.users USING btree (email);
+
+CREATE UNIQUE INDEX users_lower_email ON public.users USING btree (lower((email)::text));
@@ -0,0 +1,329 @@
+package squbix
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenQuotedIdentifier
+	tokenString
+	tokenNumber
+	tokenPunctuation
+	tokenOperator
+	tokenComment
+)
+
+// token is lexical unit of sql text, start and end are byte offsets in the tokenized text.
+type token struct {
+	kind  tokenKind
+	text  string
+	start int
+	end   int
+}
+
+// is reports whether token is the keyword or punctuation, keywords are compared case insensitively.
+func (ths token) is(text string) bool {
+	if ths.kind != tokenWord && ths.kind != tokenPunctuation && ths.kind != tokenOperator {
+		return false
+	}
+
+	return strings.EqualFold(ths.text, text)
+}
+
+// tokenize splits sql text into tokens, skipping whitespace. String literals, quoted identifiers
+// and comments are kept as single tokens so their content never affects the structure of query.
+func tokenize(sql string) ([]token, error) {
+	return tokenizeFor(sql, Standard)
+}
+
+// tokenizeFor splits sql text of the dialect into tokens, see tokenize. Backslash escapes quote in
+// every string literal of mysql, other dialects honor it only in E'...' strings.
+func tokenizeFor(sql string, dialect Dialect) ([]token, error) {
+	tokens, err := lex(sql, dialect == MySQL)
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// lex splits sql text into tokens, returning tokens preceding the error together with it.
+func lex(sql string, backslash bool) ([]token, error) {
+	tokens := []token{}
+
+	for position := 0; position < len(sql); {
+		char, size := utf8.DecodeRuneInString(sql[position:])
+		start := position
+
+		switch {
+		case unicode.IsSpace(char):
+			position += size
+
+			continue
+		case strings.HasPrefix(sql[position:], "--"):
+			position = indexFrom(sql, "\n", position)
+			tokens = append(tokens, token{kind: tokenComment, text: sql[start:position], start: start, end: position})
+
+			continue
+		case strings.HasPrefix(sql[position:], "/*"):
+			end := strings.Index(sql[position+2:], "*/")
+			if end < 0 {
+				return tokens, fmt.Errorf("unterminated comment at offset %d", start)
+			}
+
+			position += end + 4
+			tokens = append(tokens, token{kind: tokenComment, text: sql[start:position], start: start, end: position})
+
+			continue
+		}
+
+		kind, end, err := scanToken(sql, position, backslash)
+		if err != nil {
+			return tokens, err
+		}
+
+		position = end
+		tokens = append(tokens, token{kind: kind, text: sql[start:end], start: start, end: end})
+	}
+
+	return tokens, nil
+}
+
+// scanToken scans single non whitespace, non comment token starting at position.
+func scanToken(sql string, position int, backslash bool) (tokenKind, int, error) {
+	char := sql[position]
+
+	switch {
+	case char == '\'':
+		end, err := scanQuoted(sql, position, '\'', backslash)
+
+		return tokenString, end, err
+	case (char == 'E' || char == 'e') && position+1 < len(sql) && sql[position+1] == '\'':
+		end, err := scanQuoted(sql, position+1, '\'', true)
+
+		return tokenString, end, err
+	case char == '"' && backslash:
+		end, err := scanQuoted(sql, position, char, true)
+
+		return tokenString, end, err
+	case char == '"' || char == '`':
+		end, err := scanQuoted(sql, position, char, false)
+
+		return tokenQuotedIdentifier, end, err
+	case char == '$' && position+1 < len(sql) && isDigit(sql[position+1]):
+		end := position + 1
+		for end < len(sql) && isDigit(sql[end]) {
+			end++
+		}
+
+		return tokenWord, end, nil
+	case char == '$':
+		if end, ok := scanDollarQuoted(sql, position); ok {
+			return tokenString, end, nil
+		}
+
+		return tokenOperator, position + 1, nil
+	case isDigit(char) || (char == '.' && position+1 < len(sql) && isDigit(sql[position+1])):
+		end := position
+		for end < len(sql) && (isDigit(sql[end]) || sql[end] == '.' || sql[end] == 'e' || sql[end] == 'E') {
+			end++
+		}
+
+		return tokenNumber, end, nil
+	case char >= utf8.RuneSelf || isWordStart(rune(char)):
+		end := position
+		for end < len(sql) {
+			r, size := utf8.DecodeRuneInString(sql[end:])
+			if !isWordPart(r) && (r < utf8.RuneSelf || unicode.IsSpace(r)) {
+				break
+			}
+
+			end += size
+		}
+
+		return tokenWord, end, nil
+	case strings.ContainsRune("(),;.[]", rune(char)):
+		return tokenPunctuation, position + 1, nil
+	}
+
+	end := position + 1
+	for end < len(sql) && strings.ContainsRune("+-*/<>=~!@#%^&|?:", rune(sql[end])) {
+		if strings.HasPrefix(sql[end:], "--") || strings.HasPrefix(sql[end:], "/*") {
+			break
+		}
+
+		end++
+	}
+
+	return tokenOperator, end, nil
+}
+
+// scanQuoted scans text quoted by quote starting at position, doubled quote is treated as escaped
+// quote and backslash escapes are honored when requested.
+func scanQuoted(sql string, position int, quote byte, backslash bool) (int, error) {
+	for end := position + 1; end < len(sql); end++ {
+		switch {
+		case backslash && sql[end] == '\\':
+			end++
+		case sql[end] == quote && end+1 < len(sql) && sql[end+1] == quote:
+			end++
+		case sql[end] == quote:
+			return end + 1, nil
+		}
+	}
+
+	if quote == '\'' {
+		return 0, fmt.Errorf("unterminated string literal at offset %d", position)
+	}
+
+	return 0, fmt.Errorf("unterminated quoted identifier at offset %d", position)
+}
+
+// scanDollarQuoted scans postgres dollar quoted string like $$text$$ or $tag$text$tag$.
+func scanDollarQuoted(sql string, position int) (int, bool) {
+	tagEnd := position + 1
+	for tagEnd < len(sql) && isWordPart(rune(sql[tagEnd])) {
+		tagEnd++
+	}
+
+	if tagEnd >= len(sql) || sql[tagEnd] != '$' {
+		return 0, false
+	}
+
+	tag := sql[position : tagEnd+1]
+
+	end := strings.Index(sql[tagEnd+1:], tag)
+	if end < 0 {
+		return 0, false
+	}
+
+	return tagEnd + 1 + end + len(tag), true
+}
+
+func indexFrom(text string, substring string, position int) int {
+	index := strings.Index(text[position:], substring)
+	if index < 0 {
+		return len(text)
+	}
+
+	return position + index
+}
+
+func isDigit(char byte) bool {
+	return char >= '0' && char <= '9'
+}
+
+func isWordStart(char rune) bool {
+	return char == '_' || unicode.IsLetter(char)
+}
+
+func isWordPart(char rune) bool {
+	return char == '_' || unicode.IsLetter(char) || unicode.IsDigit(char)
+}
+
+// unquoteIdentifier removes identifier quotes, e.g. "users" or `users` becomes users.
+func unquoteIdentifier(identifier string) string {
+	if len(identifier) < 2 {
+		return identifier
+	}
+
+	quote := identifier[0]
+	if (quote != '"' && quote != '`') || identifier[len(identifier)-1] != quote {
+		return identifier
+	}
+
+	return strings.Replace(identifier[1:len(identifier)-1], string([]byte{quote, quote}), string(quote), -1)
+}
+
+// identifier is part of possibly qualified identifier, e.g. public or "Users" of public."Users".
+type identifier struct {
+	name   string
+	quoted bool
+}
+
+// parseIdentifier splits possibly qualified identifier into its parts, it returns false for
+// anything else, e.g. subquery.
+func parseIdentifier(text string) ([]identifier, bool) {
+	tokens, err := tokenize(text)
+	if err != nil || len(tokens)%2 == 0 {
+		return nil, false
+	}
+
+	parts := make([]identifier, 0, len(tokens)/2+1)
+
+	for index, current := range tokens {
+		if index%2 == 1 {
+			if !current.is(".") {
+				return nil, false
+			}
+
+			continue
+		}
+
+		if current.kind != tokenWord && current.kind != tokenQuotedIdentifier {
+			return nil, false
+		}
+
+		parts = append(parts, identifier{
+			name:   unquoteIdentifier(current.text),
+			quoted: current.kind == tokenQuotedIdentifier,
+		})
+	}
+
+	return parts, true
+}
+
+// matches reports whether identifiers refer to the same name, unquoted identifiers are compared
+// case insensitively and quoted ones exactly.
+func (ths identifier) matches(other identifier) bool {
+	if ths.quoted || other.quoted {
+		return ths.name == other.name
+	}
+
+	return strings.EqualFold(ths.name, other.name)
+}
+
+// sameTable reports whether possibly qualified table names refer to the same table, name without
+// database schema refers to table of any schema.
+func sameTable(a []identifier, b []identifier) bool {
+	if len(a) == 0 || len(b) == 0 || !a[len(a)-1].matches(b[len(b)-1]) {
+		return false
+	}
+
+	if len(a) > 1 && len(b) > 1 {
+		return a[len(a)-2].matches(b[len(b)-2])
+	}
+
+	return true
+}
+
+// splitTokens splits tokens by separator punctuation outside of parentheses.
+func splitTokens(tokens []token, separator string) [][]token {
+	parts := [][]token{}
+	depth := 0
+	start := 0
+
+	for index, current := range tokens {
+		switch {
+		case current.is("("):
+			depth++
+		case current.is(")"):
+			depth--
+		case depth == 0 && current.is(separator):
+			parts = append(parts, tokens[start:index])
+			start = index + 1
+		}
+	}
+
+	if start < len(tokens) {
+		parts = append(parts, tokens[start:])
+	}
+
+	return parts
+}
@@ -0,0 +1,59 @@
+package squbix
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func tokenTexts(tokens []token) []string {
+	texts := []string{}
+	for _, current := range tokens {
+		texts = append(texts, current.text)
+	}
+
+	return texts
+}
+
+func TestTokenize(t *testing.T) {
+	Convey("Given query with literals, quoted identifiers and comments", t, func() {
+		tokens, err := tokenize(`SELECT "a b", 'it''s; (' AS c, $$x)$$ -- note (
+			FROM t /* ) */ WHERE d::text <> E'\'' AND e = $1`)
+
+		Convey("It should keep them as single tokens", func() {
+			So(err, ShouldBeNil)
+			So(tokenTexts(tokens), ShouldResemble, []string{
+				"SELECT", `"a b"`, ",", `'it''s; ('`, "AS", "c", ",", "$$x)$$", "-- note (",
+				"FROM", "t", "/* ) */", "WHERE", "d", "::", "text", "<>", `E'\''`, "AND", "e", "=", "$1",
+			})
+		})
+	})
+
+	Convey("Given unicode identifiers and whitespace", t, func() {
+		tokens, err := tokenize("SELECT\u2003naïve\u00a0FROM t")
+
+		Convey("It should split on unicode whitespace", func() {
+			So(err, ShouldBeNil)
+			So(tokenTexts(tokens), ShouldResemble, []string{"SELECT", "naïve", "FROM", "t"})
+		})
+	})
+
+	Convey("Given unterminated quoted identifier", t, func() {
+		_, err := tokenize(`SELECT "a FROM t`)
+
+		Convey("It should returns error", func() {
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "unterminated quoted identifier at offset 7")
+		})
+	})
+
+	Convey("Given tokens separated by commas", t, func() {
+		tokens, _ := tokenize("a, f(b, c), d")
+		parts := splitTokens(tokens, ",")
+
+		Convey("It should split outside of parentheses only", func() {
+			So(len(parts), ShouldEqual, 3)
+			So(tokenTexts(parts[1]), ShouldResemble, []string{"f", "(", "b", ",", "c", ")"})
+		})
+	})
+}
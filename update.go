@@ -2,16 +2,24 @@ package squbix
 
 import (
 	"errors"
-	"fmt"
 	"strings"
 )
 
 type updateQueryBuilder struct {
 	intoFragment   string
-	setFragments   []string
-	whereFragments []string
+	setFragments   []Expr
+	whereFragments []Expr
+	guards         []Expr
+	schema         *Schema
+	lint           *LintOptions
+	rawConditions  bool
+	options        renderOptions
+	err            error
 }
 
+// UpdateQueryBuilder is sql builder for update operation, it allows the builder to be referred from other packages.
+type UpdateQueryBuilder = updateQueryBuilder
+
 // NewUpdateQuery creates new sql builder instance for update operation.
 func NewUpdateQuery(table string) *updateQueryBuilder {
 	return &updateQueryBuilder{
@@ -21,6 +29,13 @@ func NewUpdateQuery(table string) *updateQueryBuilder {
 
 // AddSetField adds field to update in generated query.
 func (ths *updateQueryBuilder) AddSetField(fields ...string) *updateQueryBuilder {
+	ths.setFragments = append(ths.setFragments, rawExprs(fields)...)
+
+	return ths
+}
+
+// AddSetFieldExpr adds field to update with bound arguments in generated query.
+func (ths *updateQueryBuilder) AddSetFieldExpr(fields ...Expr) *updateQueryBuilder {
 	ths.setFragments = append(ths.setFragments, fields...)
 
 	return ths
@@ -28,34 +43,218 @@ func (ths *updateQueryBuilder) AddSetField(fields ...string) *updateQueryBuilder
 
 // AddWhere adds where clause in generated query.
 func (ths *updateQueryBuilder) AddWhere(where ...string) *updateQueryBuilder {
+	ths.whereFragments = append(ths.whereFragments, rawExprs(where)...)
+
+	return ths
+}
+
+// AddWhereExpr adds where clause with bound arguments in generated query.
+func (ths *updateQueryBuilder) AddWhereExpr(where ...Expr) *updateQueryBuilder {
 	ths.whereFragments = append(ths.whereFragments, where...)
 
 	return ths
 }
 
+// WithSchema binds schema to validate table and fields of generated query against.
+func (ths *updateQueryBuilder) WithSchema(schema *Schema) *updateQueryBuilder {
+	ths.schema = schema
+
+	return ths
+}
+
+// WithoutAutoParenthesis joins where fragments as they are, by default fragments having top-level
+// OR are wrapped in parentheses so they don't weaken conditions they are joined with using AND.
+func (ths *updateQueryBuilder) WithoutAutoParenthesis() *updateQueryBuilder {
+	ths.rawConditions = true
+
+	return ths
+}
+
+// WithLint enables lint pass over raw fragments of generated query.
+func (ths *updateQueryBuilder) WithLint(options LintOptions) *updateQueryBuilder {
+	ths.lint = &options
+
+	return ths
+}
+
+// WithDialect sets sql dialect of generated query.
+func (ths *updateQueryBuilder) WithDialect(dialect Dialect) *updateQueryBuilder {
+	ths.options.dialect = dialect
+
+	return ths
+}
+
+// WithArrayBinding binds slice of IN (?) condition as single array argument using = ANY(?),
+// it requires postgres dialect.
+func (ths *updateQueryBuilder) WithArrayBinding() *updateQueryBuilder {
+	ths.options.arrayBinding = true
+
+	return ths
+}
+
+// WithFormat sets layout of generated query.
+func (ths *updateQueryBuilder) WithFormat(format Format) *updateQueryBuilder {
+	ths.options.format = format
+
+	return ths
+}
+
+// When calls apply with the builder only when condition is true, keeping the chain unbroken.
+func (ths *updateQueryBuilder) When(condition bool, apply func(builder *updateQueryBuilder)) *updateQueryBuilder {
+	if condition {
+		apply(ths)
+	}
+
+	return ths
+}
+
+// AddWhereIf adds where clause in generated query only when condition is true.
+func (ths *updateQueryBuilder) AddWhereIf(condition bool, where ...string) *updateQueryBuilder {
+	if condition {
+		ths.AddWhere(where...)
+	}
+
+	return ths
+}
+
+// AddWhereIfNotZero adds where clause binding value to its placeholders only when value is not
+// nil nor zero value of its type.
+func (ths *updateQueryBuilder) AddWhereIfNotZero(value interface{}, where string) *updateQueryBuilder {
+	if !isZeroValue(value) {
+		ths.AddWhereExpr(bindEach(where, value))
+	}
+
+	return ths
+}
+
+// AddWhereFilter adds where clause for each field of filter struct tagged with sq, e.g.
+// `sq:"status = ?"`, skipping nil and zero value fields.
+func (ths *updateQueryBuilder) AddWhereFilter(filter interface{}) *updateQueryBuilder {
+	conditions, err := filterConditions(filter)
+	if err != nil {
+		ths.err = err
+
+		return ths
+	}
+
+	return ths.AddWhereExpr(conditions...)
+}
+
 // BuildQuery generates final query string.
 func (ths *updateQueryBuilder) BuildQuery() (string, error) {
+	query, _, err := ths.BuildQueryWithArgs()
+
+	return query, err
+}
+
+// BuildQueryWithArgs generates final query string and arguments bound to its placeholders.
+func (ths *updateQueryBuilder) BuildQueryWithArgs() (string, []interface{}, error) {
+	expr, err := ths.buildExpr()
+	if err != nil {
+		return "", nil, err
+	}
+
+	return renderExpr(expr, ths.options)
+}
+
+// DebugString returns query with arguments inlined as escaped literals for logs, it must never be
+// executed.
+func (ths *updateQueryBuilder) DebugString() string {
+	return debugString(ths)
+}
+
+func (ths *updateQueryBuilder) buildExpr() (Expr, error) {
+	if ths.err != nil {
+		return Expr{}, ths.err
+	}
+	clauses := []clauseFragments{
+		{clause: "table", fragments: tableFragments(ths.intoFragment)},
+		{clause: "set", fragments: ths.setFragments},
+		{clause: "where", fragments: ths.whereFragments},
+	}
+	if err := checkFragments(clauses...); err != nil {
+		return Expr{}, err
+	}
+	if err := lint(ths.lint, !ths.rawConditions, clauses...); err != nil {
+		return Expr{}, err
+	}
 	if len(ths.intoFragment) == 0 {
-		return "", errors.New("no table specified for query")
+		return Expr{}, errors.New("no table specified for query")
 	}
 	if len(ths.setFragments) == 0 {
-		return "", errors.New("no field specified, add it using AddSetField method")
+		return Expr{}, errors.New("no field specified, add it using AddSetField method")
 	}
-	if len(ths.whereFragments) == 0 {
-		return "", errors.New("no update condition specified, this is DANGEROUS, add it using AddWhere method")
+	if len(ths.whereFragments) == 0 && len(ths.guards) == 0 {
+		return Expr{}, errors.New("no update condition specified, this is DANGEROUS, add it using AddWhere method")
 	}
+	if ths.schema != nil {
+		if err := ths.validateSchema(); err != nil {
+			return Expr{}, err
+		}
+	}
+
+	whereFragments := ths.whereFragments
+
+	if !ths.rawConditions {
+		whereFragments = parenthesizeOr(whereFragments, ths.options.dialect)
+	}
+
+	whereFragments = guardConditions(whereFragments, ths.guards, ths.options.dialect)
 
-	queryFragments := []string{}
+	queryFragments := []Expr{}
 
-	queryFragments = append(queryFragments, fmt.Sprintf(
+	queryFragments = append(queryFragments, formatExpr(
 		"UPDATE %s SET %s WHERE %s",
-		ths.intoFragment,
-		strings.Join(ths.setFragments, ", "),
-		strings.Join(ths.whereFragments, " AND "),
+		rawExpr(ths.intoFragment),
+		joinExprs(ths.setFragments, ", "),
+		joinExprs(whereFragments, " AND "),
 	))
 
-	query := strings.Join(queryFragments, " ")
-	query = whitespaceNormalizer.ReplaceAllString(query, " ")
+	return joinExprs(queryFragments, " "), nil
+}
+
+func (ths *updateQueryBuilder) buildOptions() renderOptions {
+	return ths.options
+}
+
+func (ths *updateQueryBuilder) shape() Shape {
+	return Shape{
+		Kind:       UpdateStatement,
+		Table:      shapeTable(ths.intoFragment),
+		Conditions: shapeConditions(ths.whereFragments),
+	}
+}
+
+// validateSchema checks table and fields to set against bound schema.
+func (ths *updateQueryBuilder) validateSchema() error {
+	scope := newSchemaScope(ths.schema)
+
+	if err := scope.addTable(ths.intoFragment); err != nil {
+		return err
+	}
+
+	fields := []string{}
+	for _, field := range exprSQLs(ths.setFragments) {
+		if column := setColumn(field); len(column) > 0 {
+			fields = append(fields, column)
+		}
+	}
+
+	return scope.validateColumns(fields)
+}
+
+// setColumn returns column assigned by set fragment, e.g. "Name" of "Name" = ?.
+func setColumn(field string) string {
+	tokens, err := tokenize(field)
+	if err != nil {
+		return ""
+	}
+
+	for _, current := range tokens {
+		if current.kind == tokenOperator && strings.HasPrefix(current.text, "=") {
+			return strings.TrimSpace(field[:current.start])
+		}
+	}
 
-	return query, nil
+	return ""
 }
@@ -52,4 +52,24 @@ func TestNewUpdateQuery(t *testing.T) {
 			So(query, ShouldEqual, "UPDATE table_a SET field_a = 'A' WHERE field_a IS NULL")
 		})
 	})
+
+	Convey("Given where fragments with top-level OR", t, func() {
+		query, err := NewUpdateQuery("table_a").
+			AddSetField("field_a = 'A'").
+			AddWhereExpr(NewExpr("field_b = ? OR field_b = ?", 1, 2)).
+			AddWhere("field_a IS NULL").
+			BuildQuery()
+		raw, rawErr := NewUpdateQuery("table_a").
+			WithoutAutoParenthesis().
+			AddSetField("field_a = 'A'").
+			AddWhere("field_b = 1 OR field_b = 2", "field_a IS NULL").
+			BuildQuery()
+
+		Convey("It should wrap them in parentheses unless disabled", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "UPDATE table_a SET field_a = 'A' WHERE (field_b = ? OR field_b = ?) AND field_a IS NULL")
+			So(rawErr, ShouldBeNil)
+			So(raw, ShouldEqual, "UPDATE table_a SET field_a = 'A' WHERE field_b = 1 OR field_b = 2 AND field_a IS NULL")
+		})
+	})
 }
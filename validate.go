@@ -0,0 +1,96 @@
+package squbix
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EmptyFragmentError is returned when fragment added to clause of query is empty or contains only
+// whitespace, such fragment would silently generate malformed query.
+type EmptyFragmentError struct {
+	// Clause is clause the fragment was added to, e.g. "where" or "order by".
+	Clause string
+}
+
+// Error returns description of the error.
+func (ths *EmptyFragmentError) Error() string {
+	return fmt.Sprintf("empty %s fragment", ths.Clause)
+}
+
+// clauseFragments is fragments added to single clause of query.
+type clauseFragments struct {
+	clause    string
+	fragments []Expr
+}
+
+// checkFragments returns EmptyFragmentError of the first clause having blank fragment.
+func checkFragments(clauses ...clauseFragments) error {
+	for _, clause := range clauses {
+		for _, fragment := range clause.fragments {
+			if len(strings.TrimSpace(fragment.SQL)) == 0 {
+				return &EmptyFragmentError{Clause: clause.clause}
+			}
+		}
+	}
+
+	return nil
+}
+
+// tableFragments returns table of builder as fragments to check, table not set at all is reported
+// as missing rather than empty.
+func tableFragments(table string) []Expr {
+	if len(table) == 0 {
+		return nil
+	}
+
+	return []Expr{rawExpr(table)}
+}
+
+// checkSyntax is lightweight validity check of query, it verifies quotes and comments are
+// terminated, parentheses are balanced and no list has empty item.
+func checkSyntax(query string) error {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return err
+	}
+
+	opened := []int{}
+
+	for index, current := range tokens {
+		switch {
+		case current.is("("):
+			opened = append(opened, current.start)
+		case current.is(")"):
+			if len(opened) == 0 {
+				return fmt.Errorf("unbalanced closing parenthesis at offset %d", current.start)
+			}
+
+			opened = opened[:len(opened)-1]
+		case current.is(",") && nextTokenIndex(tokens, index, 1) < 0:
+			return fmt.Errorf("trailing comma at offset %d", current.start)
+		case current.is(","):
+			if emptyListItem(tokens, index) {
+				return fmt.Errorf("empty list item at offset %d", current.start)
+			}
+		}
+	}
+
+	if len(opened) > 0 {
+		return fmt.Errorf("unbalanced opening parenthesis at offset %d", opened[len(opened)-1])
+	}
+
+	return nil
+}
+
+// emptyListItem reports whether comma at index has no list item before or after it.
+func emptyListItem(tokens []token, index int) bool {
+	previous := nextTokenIndex(tokens, index, -1)
+	if previous < 0 || tokens[previous].is("(") || tokens[previous].is(",") || tokens[previous].is("BY") ||
+		startsClause(tokens, previous) {
+		return true
+	}
+
+	next := nextTokenIndex(tokens, index, 1)
+
+	return next < 0 || tokens[next].is(")") || tokens[next].is(",") || startsClause(tokens, next)
+}
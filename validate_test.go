@@ -0,0 +1,62 @@
+package squbix
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestEmptyFragmentError(t *testing.T) {
+	Convey("Given builders with blank fragments", t, func() {
+		_, readErr := NewReadQuery("orders").AddSelect("id").AddWhere("status = 'paid'", " \t\n").BuildQuery()
+		_, tableErr := NewReadQuery("").AddSelect("id").BuildQuery()
+		_, orderErr := NewReadQuery("orders").AddSelect("id").AddOrderBy(" ").BuildQuery()
+		_, lockErr := NewReadQuery("orders").AddSelect("id").ForUpdate().Of("").BuildQuery()
+		_, createErr := NewCreateQuery("orders").AddField("id", "").AddValue("(1, 2)").BuildQuery()
+		_, conflictErr := NewCreateQuery("orders").AddField("id").AddValue("(1)").AddOnConflict(" ").BuildQuery()
+		_, updateErr := NewUpdateQuery("orders").AddSetField("status = 'paid'").AddWhereExpr(NewExpr("")).BuildQuery()
+		_, deleteErr := NewDeleteQuery("  ").AddWhere("id = 1").BuildQuery()
+
+		Convey("It should returns typed error naming the clause", func() {
+			clauses := []string{}
+			for _, err := range []error{readErr, tableErr, orderErr, lockErr, createErr, conflictErr, updateErr, deleteErr} {
+				emptyFragment := &EmptyFragmentError{}
+				So(errors.As(err, &emptyFragment), ShouldBeTrue)
+
+				clauses = append(clauses, emptyFragment.Clause)
+			}
+
+			So(clauses, ShouldResemble, []string{"where", "from", "order by", "lock of", "field", "on conflict", "where", "table"})
+			So(readErr.Error(), ShouldEqual, "empty where fragment")
+		})
+	})
+}
+
+func TestCheckSyntax(t *testing.T) {
+	Convey("Given well formed queries", t, func() {
+		queries := []string{
+			"SELECT id, name FROM orders WHERE status IN ('paid', 'refunded') ORDER BY id",
+			"INSERT INTO orders (id, note) VALUES (1, 'a, b'), (2, ')')",
+			"SELECT COUNT(*) FROM (SELECT id FROM orders) AS aggregated -- done",
+		}
+
+		Convey("It should pass the check", func() {
+			for _, query := range queries {
+				So(checkSyntax(query), ShouldBeNil)
+			}
+		})
+	})
+
+	Convey("Given malformed queries", t, func() {
+		Convey("It should reports the problem", func() {
+			So(checkSyntax("SELECT id FROM orders WHERE (status = 'paid'").Error(), ShouldEqual, "unbalanced opening parenthesis at offset 28")
+			So(checkSyntax("SELECT id) FROM orders").Error(), ShouldEqual, "unbalanced closing parenthesis at offset 9")
+			So(checkSyntax("SELECT id FROM orders WHERE status = 'paid").Error(), ShouldEqual, "unterminated string literal at offset 37")
+			So(checkSyntax("SELECT id, FROM orders").Error(), ShouldEqual, "empty list item at offset 9")
+			So(checkSyntax("SELECT , id FROM orders").Error(), ShouldEqual, "empty list item at offset 7")
+			So(checkSyntax("INSERT INTO orders (id,, note) VALUES (1, 2)").Error(), ShouldEqual, "empty list item at offset 22")
+			So(checkSyntax("SELECT id FROM orders ORDER BY id,").Error(), ShouldEqual, "trailing comma at offset 33")
+		})
+	})
+}
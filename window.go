@@ -0,0 +1,125 @@
+package squbix
+
+import (
+	"fmt"
+)
+
+// FrameBound is start or end of window frame.
+type FrameBound string
+
+const (
+	// UnboundedPreceding starts frame at first row of the partition.
+	UnboundedPreceding FrameBound = "UNBOUNDED PRECEDING"
+	// CurrentRow starts or ends frame at current row.
+	CurrentRow FrameBound = "CURRENT ROW"
+	// UnboundedFollowing ends frame at last row of the partition.
+	UnboundedFollowing FrameBound = "UNBOUNDED FOLLOWING"
+)
+
+// Preceding returns frame bound offset rows before current row.
+func Preceding(offset int) FrameBound {
+	return FrameBound(fmt.Sprintf("%d PRECEDING", offset))
+}
+
+// Following returns frame bound offset rows after current row.
+func Following(offset int) FrameBound {
+	return FrameBound(fmt.Sprintf("%d FOLLOWING", offset))
+}
+
+// WindowSpec is window definition of OVER and WINDOW clauses.
+type WindowSpec struct {
+	base        string
+	partitionBy []Expr
+	orderBy     []Expr
+	frame       Expr
+}
+
+// NewWindow creates new empty window definition.
+func NewWindow() *WindowSpec {
+	return &WindowSpec{}
+}
+
+// From bases the window on existing named window, e.g. one added using AddWindow.
+func (ths *WindowSpec) From(name string) *WindowSpec {
+	ths.base = name
+
+	return ths
+}
+
+// PartitionBy adds expressions to partition rows by.
+func (ths *WindowSpec) PartitionBy(exprs ...string) *WindowSpec {
+	ths.partitionBy = append(ths.partitionBy, rawExprs(exprs)...)
+
+	return ths
+}
+
+// OrderBy adds expressions to order rows of each partition by.
+func (ths *WindowSpec) OrderBy(exprs ...string) *WindowSpec {
+	ths.orderBy = append(ths.orderBy, rawExprs(exprs)...)
+
+	return ths
+}
+
+// Rows sets frame in rows between start and end.
+func (ths *WindowSpec) Rows(start FrameBound, end FrameBound) *WindowSpec {
+	return ths.between("ROWS", start, end)
+}
+
+// Range sets frame in peer groups or value range between start and end.
+func (ths *WindowSpec) Range(start FrameBound, end FrameBound) *WindowSpec {
+	return ths.between("RANGE", start, end)
+}
+
+// Groups sets frame in peer groups between start and end, mysql doesn't support GROUPS frame.
+func (ths *WindowSpec) Groups(start FrameBound, end FrameBound) *WindowSpec {
+	ths.between("GROUPS", start, end)
+	ths.frame = ths.frame.requiring("GROUPS frame", Postgres, SQLite)
+
+	return ths
+}
+
+func (ths *WindowSpec) between(unit string, start FrameBound, end FrameBound) *WindowSpec {
+	ths.frame = rawExpr(fmt.Sprintf("%s BETWEEN %s AND %s", unit, start, end))
+
+	return ths
+}
+
+// Expr returns parenthesized window definition.
+func (ths *WindowSpec) Expr() Expr {
+	fragments := []Expr{}
+
+	if len(ths.base) > 0 {
+		fragments = append(fragments, rawExpr(ths.base))
+	}
+
+	if len(ths.partitionBy) > 0 {
+		fragments = append(fragments, formatExpr(
+			"PARTITION BY %s",
+			joinExprs(ths.partitionBy, ", "),
+		))
+	}
+
+	if len(ths.orderBy) > 0 {
+		fragments = append(fragments, formatExpr(
+			"ORDER BY %s",
+			joinExprs(ths.orderBy, ", "),
+		))
+	}
+
+	if len(ths.frame.SQL) > 0 {
+		fragments = append(fragments, ths.frame)
+	}
+
+	return formatExpr("(%s)", joinExprs(fragments, " "))
+}
+
+// Over returns window function call over window definition, e.g.
+// Over("ROW_NUMBER()", NewWindow().PartitionBy("customer_id").OrderBy("created_at")).
+func Over(function string, window *WindowSpec) Expr {
+	return formatExpr("%s OVER %s", rawExpr(function), window.Expr())
+}
+
+// OverWindow returns window function call over named window added using AddWindow.
+func OverWindow(function string, name string) Expr {
+	return rawExpr(fmt.Sprintf("%s OVER %s", function, name))
+}
@@ -0,0 +1,79 @@
+package squbix
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWindow(t *testing.T) {
+	Convey("Given window function in select", t, func() {
+		query, err := NewReadQuery("orders").
+			AddSelect("id").
+			AddSelectExpr(Over("ROW_NUMBER()", NewWindow().PartitionBy("customer_id").OrderBy("created_at DESC", "id"))).
+			BuildQuery()
+
+		Convey("It should returns generated query", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT id, ROW_NUMBER() OVER (PARTITION BY customer_id ORDER BY created_at DESC, id) FROM orders")
+		})
+	})
+
+	Convey("Given named windows with frames", t, func() {
+		query, args, err := NewReadQuery("orders").
+			AddSelect("customer_id").
+			AddSelectExpr(
+				OverWindow("SUM(total)", "running"),
+				Over("AVG(total)", NewWindow().From("customer").Rows(Preceding(2), CurrentRow)),
+			).
+			AddWhereExpr(NewExpr("status = ?", "paid")).
+			AddGroupBy("customer_id", "total", "created_at").
+			AddHavingExpr(NewExpr("COUNT(*) > ?", 1)).
+			AddWindow("customer", NewWindow().PartitionBy("customer_id").OrderBy("created_at")).
+			AddWindow("running", NewWindow().From("customer").Range(UnboundedPreceding, CurrentRow)).
+			AddOrderBy("customer_id").
+			AddLimit(10).
+			BuildQueryWithArgs()
+
+		Convey("It should render window clause between having and order by", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT customer_id, SUM(total) OVER running, AVG(total) OVER (customer ROWS BETWEEN 2 PRECEDING AND CURRENT ROW) FROM orders WHERE status = ? GROUP BY customer_id, total, created_at HAVING COUNT(*) > ? WINDOW customer AS (PARTITION BY customer_id ORDER BY created_at), running AS (customer RANGE BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW) ORDER BY customer_id LIMIT 10")
+			So(args, ShouldResemble, []interface{}{"paid", 1})
+		})
+	})
+
+	Convey("Given empty window and groups frame", t, func() {
+		query, err := NewReadQuery("orders").
+			AddSelectExpr(
+				Over("COUNT(*)", NewWindow()),
+				Over("MAX(total)", NewWindow().OrderBy("total").Groups(Preceding(1), Following(1))),
+				Over("MIN(total)", NewWindow().Rows(CurrentRow, UnboundedFollowing)),
+			).
+			AddHaving("COUNT(*) > 1").
+			BuildQuery()
+
+		Convey("It should returns generated query", func() {
+			So(err, ShouldBeNil)
+			So(query, ShouldEqual, "SELECT COUNT(*) OVER (), MAX(total) OVER (ORDER BY total GROUPS BETWEEN 1 PRECEDING AND 1 FOLLOWING), MIN(total) OVER (ROWS BETWEEN CURRENT ROW AND UNBOUNDED FOLLOWING) FROM orders HAVING COUNT(*) > 1")
+		})
+	})
+
+	Convey("Given groups frame on mysql and sqlite", t, func() {
+		window := NewWindow().OrderBy("total").Groups(UnboundedPreceding, CurrentRow)
+
+		_, mysqlErr := NewReadQuery("orders").
+			WithDialect(MySQL).
+			AddSelectExpr(Over("SUM(total)", window)).
+			BuildQuery()
+		sqlite, sqliteErr := NewReadQuery("orders").
+			WithDialect(SQLite).
+			AddSelectExpr(Over("SUM(total)", window)).
+			BuildQuery()
+
+		Convey("It should returns error on mysql only", func() {
+			So(mysqlErr, ShouldBeError, "GROUPS frame is not supported by mysql dialect")
+			So(sqliteErr, ShouldBeNil)
+			So(sqlite, ShouldEqual, "SELECT SUM(total) OVER (ORDER BY total GROUPS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW) FROM orders")
+		})
+	})
+}